@@ -0,0 +1,318 @@
+// Package api serves a JSON API for triggering scans, reading reports,
+// applying item actions and managing exceptions, for Home Assistant and
+// scripts. Every route except /health requires the configured token.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-unraid-clean/internal/apply"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/daemon"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/state"
+)
+
+type Server struct {
+	ctx     context.Context
+	cfgPath string
+	token   string
+	jobs    jobs
+}
+
+// New returns the API server. Scans started through it run until ctx is
+// cancelled.
+func New(ctx context.Context, cfgPath string, cfg config.API) *Server {
+	return &Server{ctx: ctx, cfgPath: cfgPath, token: cfg.Token}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.health)
+	mux.Handle("POST /scans", s.requireToken(s.startScan))
+	mux.Handle("GET /scans", s.requireToken(s.listScans))
+	mux.Handle("GET /scans/{id}", s.requireToken(s.getScan))
+	mux.Handle("GET /reports", s.requireToken(s.listReports))
+	mux.Handle("GET /reports/{id}", s.requireToken(s.getReport))
+	mux.Handle("POST /reports/{id}/items/{key}/actions", s.requireToken(s.itemAction))
+	mux.Handle("GET /exceptions", s.requireToken(s.getExceptions))
+	mux.Handle("PUT /exceptions", s.requireToken(s.putExceptions))
+	return mux
+}
+
+// requireToken accepts the token as "Authorization: Bearer <token>" or in an
+// X-Api-Key header.
+func (s *Server) requireToken(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			writeError(w, http.StatusForbidden, errors.New("api.token is not configured"))
+			return
+		}
+		token := r.Header.Get("X-Api-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			token = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing API token"))
+			return
+		}
+		next(w, r)
+	})
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) startScan(w http.ResponseWriter, r *http.Request) {
+	job, started := s.jobs.start(s.ctx, s.cfgPath)
+	if !started {
+		writeJSON(w, http.StatusConflict, job)
+		return
+	}
+	w.Header().Set("Location", "/api/scans/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) listScans(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.jobs.all())
+}
+
+func (s *Server) getScan(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("scan job not found"))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+type reportSummary struct {
+	ID string `json:"id"`
+}
+
+func (s *Server) listReports(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.Load(s.cfgPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	paths, err := state.FromConfig(cfg).Reports(daemon.ScanPrefix)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]reportSummary, 0, len(paths))
+	for _, path := range paths {
+		out = append(out, reportSummary{ID: state.ReportID(path)})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// reportItem is a report item with its key and apply status.
+type reportItem struct {
+	Key          string `json:"key"`
+	Status       string `json:"status,omitempty"`
+	StatusReason string `json:"status_reason,omitempty"`
+	report.Item
+}
+
+type reportResponse struct {
+	ID                 string       `json:"id"`
+	GeneratedAt        time.Time    `json:"generated_at"`
+	ReclaimTargetBytes int64        `json:"reclaim_target_bytes,omitempty"`
+	Items              []reportItem `json:"items"`
+}
+
+func (s *Server) getReport(w http.ResponseWriter, r *http.Request) {
+	_, path, rep, err := s.loadReport(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	progress, err := apply.LoadProgress(apply.ProgressPath(path))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	resp := reportResponse{
+		ID:                 state.ReportID(path),
+		GeneratedAt:        rep.GeneratedAt,
+		ReclaimTargetBytes: rep.ReclaimTargetBytes,
+		Items:              make([]reportItem, 0, len(rep.Items)),
+	}
+	for _, item := range rep.Items {
+		entry := reportItem{Key: item.Key(), Item: item}
+		if progress != nil {
+			entry.Status = progress.Items[entry.Key].Status
+			entry.StatusReason = progress.Items[entry.Key].Reason
+		}
+		resp.Items = append(resp.Items, entry)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type actionRequest struct {
+	Action string `json:"action"`
+	// Revalidate re-checks destructive actions against live data first; it
+	// defaults to true.
+	Revalidate *bool `json:"revalidate"`
+}
+
+type actionResponse struct {
+	Key    string `json:"key"`
+	Title  string `json:"title"`
+	Action string `json:"action"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (s *Server) itemAction(w http.ResponseWriter, r *http.Request) {
+	var req actionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// NormalizeAction turns "" into delete, which must never happen by
+	// omission here.
+	action, err := report.NormalizeAction(req.Action)
+	if err != nil || strings.TrimSpace(req.Action) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("action must be one of keep, ignore, delete, delete_files, keep_last_season, quarantine"))
+		return
+	}
+	_, path, rep, err := s.loadReport(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	key := r.PathValue("key")
+	item, ok := rep.Find(key)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("item %q not found in report", key))
+		return
+	}
+	if err := report.CheckAction(item, action); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	item.Action = action
+	revalidate := req.Revalidate == nil || *req.Revalidate
+
+	_, applyErr := apply.Partial(r.Context(), s.cfgPath, path, rep, []report.Item{item}, "api", revalidate)
+	progress, err := apply.LoadProgress(apply.ProgressPath(path))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	resp := actionResponse{Key: key, Title: item.Title, Action: action}
+	if progress != nil {
+		resp.Status = progress.Items[key].Status
+		resp.Reason = progress.Items[key].Reason
+	}
+	if applyErr != nil {
+		resp.Status = apply.StatusFailed
+		resp.Reason = applyErr.Error()
+		writeJSON(w, http.StatusBadGateway, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getExceptions(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.Load(s.cfgPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, cfg.Exceptions)
+}
+
+// putExceptions replaces the whole exception list.
+func (s *Server) putExceptions(w http.ResponseWriter, r *http.Request) {
+	var exceptions config.Exceptions
+	if err := decodeJSON(r, &exceptions); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	status := http.StatusOK
+	var cfg config.Config
+	err := apply.WithConfigLock(func() error {
+		var err error
+		cfg, err = config.Load(s.cfgPath)
+		if err != nil {
+			status = http.StatusInternalServerError
+			return err
+		}
+		cfg.Exceptions = exceptions
+		if err := cfg.Validate(); err != nil {
+			status = http.StatusBadRequest
+			return err
+		}
+		if err := config.Save(s.cfgPath, cfg); err != nil {
+			status = http.StatusInternalServerError
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	logging.L().Info().Str("path", s.cfgPath).Msg("Saved exceptions from API")
+	writeJSON(w, http.StatusOK, cfg.Exceptions)
+}
+
+var errReportNotFound = errors.New("report not found")
+
+// loadReport resolves a report ID, or "latest", to a saved scan report.
+func (s *Server) loadReport(id string) (config.Config, string, *report.Report, error) {
+	cfg, err := config.Load(s.cfgPath)
+	if err != nil {
+		return cfg, "", nil, err
+	}
+	path, err := state.FromConfig(cfg).FindReport(daemon.ScanPrefix, id)
+	if err != nil {
+		return cfg, "", nil, err
+	}
+	if path == "" {
+		return cfg, "", nil, fmt.Errorf("%w: %s", errReportNotFound, id)
+	}
+	rep, err := report.ReadJSON(path)
+	return cfg, path, rep, err
+}
+
+func statusFor(err error) int {
+	if errors.Is(err, errReportNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func decodeJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.L().Debug().Err(err).Msg("Write response failed")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
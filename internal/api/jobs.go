@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/daemon"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/state"
+)
+
+const (
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+
+	// maxJobs is how many finished scan jobs are remembered.
+	maxJobs = 50
+)
+
+// Job is a scan started through the API.
+type Job struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Report     string     `json:"report,omitempty"`
+	Items      int        `json:"items,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// jobs runs one scan at a time and keeps the most recent jobs in memory.
+type jobs struct {
+	mu   sync.Mutex
+	seq  int
+	list []*Job
+}
+
+// start launches a scan unless one is already running, in which case that
+// job is returned with started false.
+func (j *jobs) start(ctx context.Context, cfgPath string) (Job, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, job := range j.list {
+		if job.Status == jobRunning {
+			return *job, false
+		}
+	}
+	j.seq++
+	job := &Job{ID: strconv.Itoa(j.seq), Status: jobRunning, StartedAt: time.Now().UTC()}
+	j.list = append(j.list, job)
+	if len(j.list) > maxJobs {
+		j.list = j.list[len(j.list)-maxJobs:]
+	}
+	go j.run(ctx, cfgPath, job)
+	return *job, true
+}
+
+func (j *jobs) run(ctx context.Context, cfgPath string, job *Job) {
+	log := logging.L().With().Str("job", job.ID).Logger()
+	log.Info().Msg("Scan started")
+	path, items, err := scan(ctx, cfgPath)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	if err != nil {
+		job.Status = jobFailed
+		job.Error = err.Error()
+		log.Error().Err(err).Msg("Scan failed")
+		return
+	}
+	job.Status = jobSucceeded
+	job.Report = state.ReportID(path)
+	job.Items = items
+	log.Info().Str("report", job.Report).Msg("Scan finished")
+}
+
+func scan(ctx context.Context, cfgPath string) (string, int, error) {
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return "", 0, err
+	}
+	path, rep, err := daemon.Scan(ctx, cfg)
+	if err != nil {
+		return "", 0, err
+	}
+	return path, len(rep.Items), nil
+}
+
+func (j *jobs) get(id string) (Job, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, job := range j.list {
+		if job.ID == id {
+			return *job, true
+		}
+	}
+	return Job{}, false
+}
+
+// all returns the remembered jobs, newest first.
+func (j *jobs) all() []Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	out := make([]Job, 0, len(j.list))
+	for idx := len(j.list) - 1; idx >= 0; idx-- {
+		out = append(out, *j.list[idx])
+	}
+	return out
+}
@@ -0,0 +1,388 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-unraid-clean/internal/arr"
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/journal"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/pathmap"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/scan"
+	"go-unraid-clean/internal/state"
+)
+
+type Executor struct {
+	cfg     *config.Config
+	arr     *arr.Clients
+	journal *journal.Journal
+
+	overseerr *clients.OverseerrClient
+	requests  []clients.OverseerrRequest
+	torrents  clients.TorrentClient
+	paths     *pathmap.Set
+	orphans   *scan.OrphanGuard
+}
+
+type Result struct {
+	Action      string
+	Exceptions  []string
+	BytesFreed  int64
+	FileIDs     []int
+	Destination string
+	Torrents    []string
+}
+
+func NewExecutor(cfg *config.Config, origin journal.Origin) (*Executor, error) {
+	arrs, err := arr.New(*cfg)
+	if err != nil {
+		return nil, err
+	}
+	executor := &Executor{
+		cfg:     cfg,
+		arr:     arrs,
+		journal: journal.Open(state.FromConfig(*cfg).JournalPath(), origin),
+		paths:   pathmap.FromConfig(*cfg),
+	}
+	if cfg.Overseerr.ClearOnDelete && cfg.Overseerr.BaseURL != "" {
+		executor.overseerr, err = clients.NewOverseerrClient(cfg.Overseerr.BaseURL, cfg.Overseerr.APIKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Torrent.Client != "" && cfg.Torrent.RemoveOnDelete {
+		executor.torrents, err = clients.NewTorrentClient(cfg.Torrent.Client, cfg.Torrent.BaseURL, cfg.Torrent.Username, cfg.Torrent.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return executor, nil
+}
+
+func (e *Executor) Execute(ctx context.Context, item report.Item, action string) (Result, error) {
+	result := Result{Action: action}
+	switch action {
+	case report.ActionKeep:
+		logging.L().Debug().Str("title", item.Title).Msg("Keeping item")
+		return result, nil
+	case report.ActionIgnore:
+		changes, err := AddException(e.cfg, item)
+		if err != nil {
+			return result, err
+		}
+		result.Exceptions = changes
+		return result, nil
+	}
+
+	if err := report.CheckAction(item, action); err != nil {
+		return result, err
+	}
+	var err error
+	switch action {
+	case report.ActionDelete:
+		hashes := e.torrentHashes(ctx, item)
+		err = e.deleteItem(ctx, item, &result)
+		if err == nil {
+			e.clearRequest(ctx, item)
+			e.removeTorrents(ctx, item, hashes, &result)
+		}
+	case report.ActionDeleteFiles:
+		hashes := e.torrentHashes(ctx, item)
+		err = e.deleteFilesOnly(ctx, item, &result)
+		if err == nil {
+			e.removeTorrents(ctx, item, hashes, &result)
+		}
+	case report.ActionKeepLastSeason:
+		err = e.keepLastSeason(ctx, item, &result)
+	case report.ActionQuarantine:
+		err = e.quarantine(ctx, item, &result)
+	default:
+		return result, fmt.Errorf("unsupported action %q", action)
+	}
+	e.record(item, result, err)
+	return result, err
+}
+
+// localPath returns the host path of an item, mapping the Arr path for
+// reports written before local paths were recorded.
+func (e *Executor) localPath(item report.Item) string {
+	if item.LocalPath != "" {
+		return item.LocalPath
+	}
+	return e.paths.ForType(item.Type, item.Instance).Map(item.Path)
+}
+
+func (e *Executor) record(item report.Item, result Result, actionErr error) {
+	entry := journal.Entry{
+		Action:       result.Action,
+		Type:         item.Type,
+		Title:        item.Title,
+		Instance:     item.Instance,
+		RadarrID:     item.RadarrID,
+		SonarrID:     item.SonarrID,
+		TMDBID:       item.TMDBID,
+		TVDBID:       item.TVDBID,
+		IMDBID:       item.IMDBID,
+		SeasonNumber: item.SeasonNumber,
+		Path:         item.Path,
+		BytesFreed:   result.BytesFreed,
+		FileIDs:      result.FileIDs,
+		Destination:  result.Destination,
+		Torrents:     result.Torrents,
+		Success:      actionErr == nil,
+	}
+	if actionErr != nil {
+		entry.Error = actionErr.Error()
+	}
+	if err := e.journal.Record(entry); err != nil {
+		logging.L().Error().Err(err).Str("title", item.Title).Msg("Failed to write journal entry")
+	}
+}
+
+func (e *Executor) deleteItem(ctx context.Context, item report.Item, result *Result) error {
+	switch item.Type {
+	case "movie":
+		if item.RadarrID == nil {
+			return fmt.Errorf("missing radarr_id")
+		}
+		radarr, err := e.arr.RadarrFor(item.Instance)
+		if err != nil {
+			return err
+		}
+		if files, err := radarr.MovieFiles(ctx, *item.RadarrID); err == nil {
+			for _, file := range files {
+				result.FileIDs = append(result.FileIDs, file.ID)
+			}
+		} else {
+			logging.L().Debug().Err(err).Str("title", item.Title).Msg("Unable to list movie files before delete")
+		}
+		logging.L().Debug().Str("title", item.Title).Str("instance", radarr.Name).Int("radarr_id", *item.RadarrID).Msg("Deleting movie")
+		if err := radarr.DeleteMovie(ctx, *item.RadarrID, true); err != nil {
+			return err
+		}
+		result.BytesFreed = item.Reclaimable()
+		return nil
+	case "series":
+		if item.SonarrID == nil {
+			return fmt.Errorf("missing sonarr_id")
+		}
+		sonarr, err := e.arr.SonarrFor(item.Instance)
+		if err != nil {
+			return err
+		}
+		if files, err := sonarr.EpisodeFiles(ctx, *item.SonarrID); err == nil {
+			for _, file := range files {
+				result.FileIDs = append(result.FileIDs, file.ID)
+			}
+		} else {
+			logging.L().Debug().Err(err).Str("title", item.Title).Msg("Unable to list episode files before delete")
+		}
+		logging.L().Debug().Str("title", item.Title).Str("instance", sonarr.Name).Int("sonarr_id", *item.SonarrID).Msg("Deleting series")
+		if err := sonarr.DeleteSeries(ctx, *item.SonarrID, true); err != nil {
+			return err
+		}
+		result.BytesFreed = item.Reclaimable()
+		if err := waitForSeriesRemoval(ctx, sonarr.SonarrClient, *item.SonarrID); err != nil {
+			return err
+		}
+		return nil
+	case "season":
+		if err := e.deleteSeasonFiles(ctx, item, result); err != nil {
+			return err
+		}
+		if !e.cfg.Rules.Seasons.Unmonitor {
+			return nil
+		}
+		sonarr, err := e.arr.SonarrFor(item.Instance)
+		if err != nil {
+			return err
+		}
+		logging.L().Debug().Str("title", item.Title).Msg("Unmonitoring season")
+		if err := sonarr.SetSeasonMonitored(ctx, *item.SonarrID, *item.SeasonNumber, false); err != nil {
+			return fmt.Errorf("series %s (%d): unmonitor season: %w", item.Title, *item.SonarrID, err)
+		}
+		return nil
+	case "orphan":
+		return e.deleteOrphan(ctx, item, result)
+	default:
+		return fmt.Errorf("unsupported item type: %s", item.Type)
+	}
+}
+
+func (e *Executor) deleteFilesOnly(ctx context.Context, item report.Item, result *Result) error {
+	switch item.Type {
+	case "orphan":
+		return e.deleteOrphan(ctx, item, result)
+	case "movie":
+		if item.RadarrID == nil {
+			return fmt.Errorf("missing radarr_id")
+		}
+		radarr, err := e.arr.RadarrFor(item.Instance)
+		if err != nil {
+			return err
+		}
+		logging.L().Debug().Str("title", item.Title).Str("instance", radarr.Name).Int("radarr_id", *item.RadarrID).Msg("Deleting movie files only")
+		files, err := radarr.MovieFiles(ctx, *item.RadarrID)
+		if err != nil {
+			return fmt.Errorf("movie %s (%d): %w", item.Title, *item.RadarrID, err)
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no movie files found")
+		}
+		logging.L().Debug().Int("count", len(files)).Msg("Movie files to delete")
+		for _, file := range files {
+			if err := radarr.DeleteMovieFile(ctx, file.ID); err != nil {
+				return fmt.Errorf("movie %s (%d): %w", item.Title, *item.RadarrID, err)
+			}
+			result.FileIDs = append(result.FileIDs, file.ID)
+			result.BytesFreed += file.Size
+		}
+		return nil
+	case "series":
+		if item.SonarrID == nil {
+			return fmt.Errorf("missing sonarr_id")
+		}
+		sonarr, err := e.arr.SonarrFor(item.Instance)
+		if err != nil {
+			return err
+		}
+		logging.L().Debug().Str("title", item.Title).Str("instance", sonarr.Name).Int("sonarr_id", *item.SonarrID).Msg("Deleting all episode files")
+		files, err := episodeFiles(ctx, sonarr.SonarrClient, *item.SonarrID, 0)
+		if err != nil {
+			return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no episode files found")
+		}
+		logging.L().Debug().Int("count", len(files)).Msg("Episode files to delete")
+		return deleteEpisodeFiles(ctx, sonarr.SonarrClient, item, files, result)
+	case "season":
+		return e.deleteSeasonFiles(ctx, item, result)
+	default:
+		return fmt.Errorf("unsupported item type: %s", item.Type)
+	}
+}
+
+func (e *Executor) keepLastSeason(ctx context.Context, item report.Item, result *Result) error {
+	if item.SonarrID == nil {
+		return fmt.Errorf("missing sonarr_id")
+	}
+	sonarr, err := e.arr.SonarrFor(item.Instance)
+	if err != nil {
+		return err
+	}
+	logging.L().Debug().Str("title", item.Title).Str("instance", sonarr.Name).Int("sonarr_id", *item.SonarrID).Msg("Fetching series detail for last season")
+	series, err := sonarr.SeriesByID(ctx, *item.SonarrID)
+	if err != nil {
+		return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
+	}
+	lastSeason := 0
+	for _, season := range series.Seasons {
+		if season.SeasonNumber == 0 {
+			continue
+		}
+		if season.Statistics.EpisodeFileCount == 0 {
+			continue
+		}
+		if season.SeasonNumber > lastSeason {
+			lastSeason = season.SeasonNumber
+		}
+	}
+	if lastSeason == 0 {
+		return fmt.Errorf("no seasons with files found")
+	}
+	logging.L().Debug().Int("season", lastSeason).Msg("Keeping last season")
+	files, err := episodeFiles(ctx, sonarr.SonarrClient, *item.SonarrID, lastSeason)
+	if err != nil {
+		return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no episode files found to delete")
+	}
+	logging.L().Debug().Int("count", len(files)).Msg("Episode files to delete (keeping last season)")
+	return deleteEpisodeFiles(ctx, sonarr.SonarrClient, item, files, result)
+}
+
+func (e *Executor) deleteSeasonFiles(ctx context.Context, item report.Item, result *Result) error {
+	if item.SonarrID == nil {
+		return fmt.Errorf("missing sonarr_id")
+	}
+	if item.SeasonNumber == nil {
+		return fmt.Errorf("missing season_number")
+	}
+	sonarr, err := e.arr.SonarrFor(item.Instance)
+	if err != nil {
+		return err
+	}
+	logging.L().Debug().Str("title", item.Title).Str("instance", sonarr.Name).Int("sonarr_id", *item.SonarrID).Int("season", *item.SeasonNumber).Msg("Deleting season episode files")
+	files, err := sonarr.EpisodeFiles(ctx, *item.SonarrID)
+	if err != nil {
+		return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
+	}
+	season := make([]clients.SonarrEpisodeFile, 0, len(files))
+	for _, file := range files {
+		if file.SeasonNumber == *item.SeasonNumber {
+			season = append(season, file)
+		}
+	}
+	if len(season) == 0 {
+		return fmt.Errorf("no episode files found for season %d", *item.SeasonNumber)
+	}
+	logging.L().Debug().Int("count", len(season)).Msg("Episode files to delete")
+	return deleteEpisodeFiles(ctx, sonarr.SonarrClient, item, season, result)
+}
+
+func deleteEpisodeFiles(ctx context.Context, sonarr *clients.SonarrClient, item report.Item, files []clients.SonarrEpisodeFile, result *Result) error {
+	for _, file := range files {
+		if err := sonarr.DeleteEpisodeFile(ctx, file.ID); err != nil {
+			return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
+		}
+		result.FileIDs = append(result.FileIDs, file.ID)
+		result.BytesFreed += file.Size
+	}
+	return nil
+}
+
+func episodeFiles(ctx context.Context, sonarr *clients.SonarrClient, seriesID int, keepSeason int) ([]clients.SonarrEpisodeFile, error) {
+	logging.L().Debug().Int("sonarr_id", seriesID).Int("keep_season", keepSeason).Msg("Fetching episode files for series")
+	files, err := sonarr.EpisodeFiles(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]clients.SonarrEpisodeFile, 0, len(files))
+	for _, file := range files {
+		if file.SeasonNumber == 0 {
+			continue
+		}
+		if keepSeason > 0 && file.SeasonNumber >= keepSeason {
+			continue
+		}
+		out = append(out, file)
+	}
+	return out, nil
+}
+
+func waitForSeriesRemoval(ctx context.Context, sonarr *clients.SonarrClient, seriesID int) error {
+	const attempts = 5
+	delay := 500 * time.Millisecond
+	for i := 0; i < attempts; i++ {
+		exists, err := sonarr.SeriesExists(ctx, seriesID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return nil
+		}
+		logging.L().Debug().Int("sonarr_id", seriesID).Int("attempt", i+1).Msg("Series still exists after delete; waiting")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return fmt.Errorf("series still exists after delete (sonarr_id=%d)", seriesID)
+}
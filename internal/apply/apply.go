@@ -4,53 +4,287 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
-	"go-unraid-clean/internal/clients"
 	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/journal"
+	"go-unraid-clean/internal/leavingsoon"
 	"go-unraid-clean/internal/logging"
 	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/scan"
+	"go-unraid-clean/internal/state"
 )
 
-func Run(ctx context.Context, cfg config.Config, rep *report.Report) error {
+type Options struct {
+	// Command is recorded as the journal origin; "apply" when empty.
+	Command      string
+	ReportPath   string
+	ProgressPath string
+	Resume       bool
+	Revalidate   bool
+	MaxReportAge time.Duration
+	// KeepProgress keeps the progress file after a clean run and records kept
+	// items in it, for callers that apply a report a few items at a time.
+	KeepProgress bool
+	// IgnoreLeavingSoon applies destructive actions without waiting out the
+	// Leaving Soon grace period.
+	IgnoreLeavingSoon bool
+}
+
+type Outcome struct {
+	Done        int
+	Failed      int
+	Skipped     int
+	Kept        int
+	AlreadyDone int
+	Remaining   int
+	Interrupted bool
+	// Applied lists the items done in this run with the action taken.
+	Applied []Applied
+}
+
+// Applied is an item an action was applied to and the space that freed.
+type Applied struct {
+	Item       report.Item
+	BytesFreed int64
+}
+
+func Run(ctx context.Context, cfgPath string, cfg config.Config, rep *report.Report, opts Options) (Outcome, error) {
 	log := logging.L()
-	radarr, err := clients.NewRadarrClient(cfg.Radarr.BaseURL, cfg.Radarr.APIKey)
-	if err != nil {
-		return err
+	var outcome Outcome
+	if err := CheckReportAge(rep, opts.MaxReportAge, time.Now().UTC()); err != nil {
+		return outcome, err
+	}
+
+	var progress *Progress
+	if opts.ProgressPath != "" {
+		existing, err := LoadProgress(opts.ProgressPath)
+		if err != nil {
+			return outcome, err
+		}
+		switch {
+		case existing != nil && !opts.Resume:
+			return outcome, fmt.Errorf("progress file %s exists from an earlier run; pass --resume to continue or remove it to start over", opts.ProgressPath)
+		case existing != nil:
+			log.Info().Str("path", opts.ProgressPath).Int("recorded", len(existing.Items)).Msg("Resuming from progress file")
+			progress = existing
+		default:
+			if opts.Resume {
+				log.Warn().Str("path", opts.ProgressPath).Msg("No progress file found; starting from the beginning")
+			}
+			progress = newProgress(opts.ProgressPath, opts.ReportPath)
+		}
+	}
+
+	command := opts.Command
+	if command == "" {
+		command = "apply"
 	}
-	sonarr, err := clients.NewSonarrClient(cfg.Sonarr.BaseURL, cfg.Sonarr.APIKey)
+	executor, err := NewExecutor(&cfg, journal.Origin{Command: command, Report: opts.ReportPath})
 	if err != nil {
-		return err
+		return outcome, err
+	}
+	var revalidator *scan.Revalidator
+	if opts.Revalidate {
+		revalidator, err = scan.NewRevalidator(ctx, cfg)
+		if err != nil {
+			return outcome, err
+		}
+	}
+
+	var leaving *leavingsoon.State
+	if cfg.LeavingSoon.Enabled && !opts.IgnoreLeavingSoon {
+		leaving, err = leavingsoon.Load(state.FromConfig(cfg))
+		if err != nil {
+			return outcome, err
+		}
+		if !leaving.Synced() {
+			log.Warn().Msg("leaving_soon is enabled but serve has never synced the collection; applying without the Leaving Soon grace period")
+			leaving = nil
+		}
 	}
 
-	log.Info().Int("count", len(rep.Items)).Msg("Applying deletions")
+	log.Info().Int("count", len(rep.Items)).Msg("Applying actions")
+	changedConfig := false
 	var errs []error
-	for _, item := range rep.Items {
-		switch item.Type {
-		case "movie":
-			if item.RadarrID == nil {
-				errs = append(errs, fmt.Errorf("movie %q has no radarr_id", item.Title))
+	for idx, item := range rep.Items {
+		if ctx.Err() != nil {
+			outcome.Interrupted = true
+			outcome.Remaining = len(rep.Items) - idx
+			log.Warn().Int("remaining", outcome.Remaining).Msg("Interrupted; stopping before remaining items")
+			break
+		}
+		key := item.Key()
+		if progress.status(key) == StatusDone {
+			log.Debug().Str("title", item.Title).Msg("Already applied in earlier run")
+			outcome.AlreadyDone++
+			continue
+		}
+		action, err := report.NormalizeAction(item.Action)
+		if err == nil {
+			err = report.CheckAction(item, action)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", item.Type, item.Title, err))
+			outcome.Failed++
+			errs = appendErr(errs, progress.mark(key, item.Title, item.Action, StatusFailed, err.Error()))
+			continue
+		}
+		if action == report.ActionKeep {
+			log.Debug().Str("title", item.Title).Msg("Keeping item")
+			outcome.Kept++
+			if opts.KeepProgress {
+				errs = appendErr(errs, progress.mark(key, item.Title, action, StatusDone, ""))
+			}
+			continue
+		}
+		if leaving != nil && isDestructive(action) {
+			if hold := leaving.Hold(item, cfg.LeavingSoon, time.Now().UTC()); hold != "" {
+				log.Info().Str("title", item.Title).Str("reason", hold).Msg("Holding item in Leaving Soon")
+				outcome.Skipped++
+				errs = appendErr(errs, progress.mark(key, item.Title, action, StatusSkipped, hold))
 				continue
 			}
-			log.Info().Str("title", item.Title).Int("radarr_id", *item.RadarrID).Msg("Deleting movie")
-			if err := radarr.DeleteMovie(ctx, *item.RadarrID, true); err != nil {
-				errs = append(errs, err)
+		}
+		if revalidator != nil && isDestructive(action) {
+			skip, err := revalidator.Check(ctx, item)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %q: revalidate: %w", item.Type, item.Title, err))
+				outcome.Failed++
+				errs = appendErr(errs, progress.mark(key, item.Title, action, StatusFailed, err.Error()))
+				continue
 			}
-		case "series":
-			if item.SonarrID == nil {
-				errs = append(errs, fmt.Errorf("series %q has no sonarr_id", item.Title))
+			if skip != "" {
+				log.Warn().Str("title", item.Title).Str("reason", skip).Msg("Skipping item that no longer qualifies")
+				outcome.Skipped++
+				errs = appendErr(errs, progress.mark(key, item.Title, action, StatusSkipped, skip))
 				continue
 			}
-			log.Info().Str("title", item.Title).Int("sonarr_id", *item.SonarrID).Msg("Deleting series")
-			if err := sonarr.DeleteSeries(ctx, *item.SonarrID, true); err != nil {
-				errs = append(errs, err)
+		}
+		log.Info().Str("title", item.Title).Str("type", item.Type).Str("action", action).Msg("Applying action")
+		result, err := executor.Execute(ctx, item, action)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", item.Type, item.Title, err))
+			outcome.Failed++
+			errs = appendErr(errs, progress.mark(key, item.Title, action, StatusFailed, err.Error()))
+			continue
+		}
+		outcome.Done++
+		item.Action = action
+		outcome.Applied = append(outcome.Applied, Applied{Item: item, BytesFreed: result.BytesFreed})
+		errs = appendErr(errs, progress.mark(key, item.Title, action, StatusDone, ""))
+		if action == report.ActionIgnore {
+			changedConfig = true
+			if len(result.Exceptions) > 0 {
+				log.Info().Str("title", item.Title).Strs("exceptions", result.Exceptions).Msg("Added to exceptions")
 			}
-		default:
-			errs = append(errs, fmt.Errorf("unsupported item type %q for %s", item.Type, item.Title))
 		}
 	}
 
+	if changedConfig {
+		if err := config.Save(cfgPath, cfg); err != nil {
+			errs = append(errs, err)
+		} else {
+			log.Info().Str("path", cfgPath).Msg("Saved config")
+		}
+	}
+
+	switch {
+	case opts.KeepProgress:
+	case outcome.Failed == 0 && !outcome.Interrupted:
+		errs = appendErr(errs, progress.remove())
+	case progress != nil:
+		log.Info().Str("path", opts.ProgressPath).Msg("Progress saved; re-run with --resume to continue")
+	}
+
+	if outcome.Interrupted {
+		errs = append(errs, ctx.Err())
+	}
 	if len(errs) > 0 {
-		return errors.Join(errs...)
+		return outcome, errors.Join(errs...)
+	}
+	return outcome, nil
+}
+
+// configMu serializes partial applies and other config writers in the same
+// process so config and progress writes from concurrent requests never
+// interleave or lose each other.
+var configMu sync.Mutex
+
+// WithConfigLock runs fn while no partial apply is running. Anything that
+// loads, changes and saves the config while the web UI or API is up must do
+// so inside fn.
+func WithConfigLock(fn func() error) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return fn()
+}
+
+// Partial applies some items of a saved report, which the web UI and API do a
+// few items at a time. It resumes the report's progress file and keeps it, so
+// handled items stay recorded for the rest of the report. The config is
+// loaded under the lock so an ignore action saves on top of the latest one.
+func Partial(ctx context.Context, cfgPath string, reportPath string, rep *report.Report, items []report.Item, command string, revalidate bool) (Outcome, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return Outcome{}, err
+	}
+	progressPath := ProgressPath(reportPath)
+	existing, err := LoadProgress(progressPath)
+	if err != nil {
+		return Outcome{}, err
+	}
+	// Revalidation fetches whole libraries, so skip it when nothing is
+	// destructive.
+	if revalidate {
+		revalidate = false
+		for _, item := range items {
+			if action, err := report.NormalizeAction(item.Action); err == nil && isDestructive(action) {
+				revalidate = true
+				break
+			}
+		}
+	}
+	return Run(ctx, cfgPath, cfg, &report.Report{GeneratedAt: rep.GeneratedAt, Items: items}, Options{
+		Command:      command,
+		ReportPath:   reportPath,
+		ProgressPath: progressPath,
+		Resume:       existing != nil,
+		Revalidate:   revalidate,
+		KeepProgress: true,
+	})
+}
+
+func appendErr(errs []error, err error) []error {
+	if err == nil {
+		return errs
+	}
+	return append(errs, err)
+}
+
+func CheckReportAge(rep *report.Report, maxAge time.Duration, now time.Time) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	if rep.GeneratedAt.IsZero() {
+		return fmt.Errorf("report has no generated_at; cannot enforce max report age")
+	}
+	age := now.Sub(rep.GeneratedAt)
+	if age > maxAge {
+		return fmt.Errorf("report is %s old (generated %s), exceeds max age %s; re-run scan", age.Round(time.Minute), rep.GeneratedAt.Format(time.RFC3339), maxAge)
 	}
 	return nil
 }
+
+func isDestructive(action string) bool {
+	switch action {
+	case report.ActionDelete, report.ActionDeleteFiles, report.ActionKeepLastSeason, report.ActionQuarantine:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,153 @@
+package apply
+
+import (
+	"fmt"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/report"
+)
+
+// AddException records an item in the config exceptions. When the item's
+// service has several instances the exception is scoped to the item's
+// instance, so ignoring a title in one instance leaves its copies in others
+// eligible for cleanup.
+func AddException(cfg *config.Config, item report.Item) ([]string, error) {
+	switch item.Type {
+	case "movie":
+		scope := exceptionScope(cfg.Radarr, item.Instance)
+		if scope == "" {
+			return addMovieException(&cfg.Exceptions.Movies, item), nil
+		}
+		scoped := cfg.Exceptions.Instances[scope]
+		changes := addMovieException(&scoped.Movies, item)
+		setScopedExceptions(cfg, scope, scoped)
+		return scopeChanges(scope, changes), nil
+	case "series", "season":
+		scope := exceptionScope(cfg.Sonarr, item.Instance)
+		if scope == "" {
+			return addSeriesException(&cfg.Exceptions.Series, item), nil
+		}
+		scoped := cfg.Exceptions.Instances[scope]
+		changes := addSeriesException(&scoped.Series, item)
+		setScopedExceptions(cfg, scope, scoped)
+		return scopeChanges(scope, changes), nil
+	case "orphan":
+		var changes []string
+		if item.LocalPath != "" {
+			before := len(cfg.Exceptions.Orphans.PathPrefixes)
+			cfg.Exceptions.Orphans.PathPrefixes = config.AddUniqueString(cfg.Exceptions.Orphans.PathPrefixes, item.LocalPath)
+			if len(cfg.Exceptions.Orphans.PathPrefixes) > before {
+				changes = append(changes, fmt.Sprintf("orphan path=%q", item.LocalPath))
+			}
+		}
+		return changes, nil
+	default:
+		return nil, fmt.Errorf("unsupported item type: %s", item.Type)
+	}
+}
+
+// exceptionScope returns the instance an exception should be scoped to, or
+// "" for the global exceptions when the service has a single instance.
+func exceptionScope(instances config.Instances, instance string) string {
+	if len(instances) <= 1 {
+		return ""
+	}
+	if inst, ok := instances.Find(instance); ok {
+		return inst.Name
+	}
+	return ""
+}
+
+func setScopedExceptions(cfg *config.Config, scope string, scoped config.InstanceExceptions) {
+	if cfg.Exceptions.Instances == nil {
+		cfg.Exceptions.Instances = map[string]config.InstanceExceptions{}
+	}
+	cfg.Exceptions.Instances[scope] = scoped
+}
+
+func scopeChanges(scope string, changes []string) []string {
+	for idx, change := range changes {
+		changes[idx] = fmt.Sprintf("%s %s", scope, change)
+	}
+	return changes
+}
+
+func addMovieException(exc *config.MovieExceptions, item report.Item) []string {
+	var changes []string
+	if item.RadarrID != nil {
+		before := len(exc.RadarrIDs)
+		exc.RadarrIDs = config.AddUniqueInt(exc.RadarrIDs, *item.RadarrID)
+		if len(exc.RadarrIDs) > before {
+			changes = append(changes, fmt.Sprintf("radarr_id=%d", *item.RadarrID))
+		}
+	}
+	if item.TMDBID != nil {
+		before := len(exc.TMDBIDs)
+		exc.TMDBIDs = config.AddUniqueInt(exc.TMDBIDs, *item.TMDBID)
+		if len(exc.TMDBIDs) > before {
+			changes = append(changes, fmt.Sprintf("tmdb_id=%d", *item.TMDBID))
+		}
+	}
+	if item.IMDBID != "" {
+		before := len(exc.IMDBIDs)
+		exc.IMDBIDs = config.AddUniqueString(exc.IMDBIDs, item.IMDBID)
+		if len(exc.IMDBIDs) > before {
+			changes = append(changes, fmt.Sprintf("imdb_id=%s", item.IMDBID))
+		}
+	}
+	if item.Title != "" {
+		before := len(exc.Titles)
+		exc.Titles = config.AddUniqueString(exc.Titles, item.Title)
+		if len(exc.Titles) > before {
+			changes = append(changes, fmt.Sprintf("title=%q", item.Title))
+		}
+	}
+	if item.Path != "" {
+		before := len(exc.PathPrefixes)
+		exc.PathPrefixes = config.AddUniqueString(exc.PathPrefixes, item.Path)
+		if len(exc.PathPrefixes) > before {
+			changes = append(changes, fmt.Sprintf("path=%q", item.Path))
+		}
+	}
+	return changes
+}
+
+func addSeriesException(exc *config.SeriesExceptions, item report.Item) []string {
+	var changes []string
+	if item.SonarrID != nil {
+		before := len(exc.SonarrIDs)
+		exc.SonarrIDs = config.AddUniqueInt(exc.SonarrIDs, *item.SonarrID)
+		if len(exc.SonarrIDs) > before {
+			changes = append(changes, fmt.Sprintf("sonarr_id=%d", *item.SonarrID))
+		}
+	}
+	if item.TVDBID != nil {
+		before := len(exc.TVDBIDs)
+		exc.TVDBIDs = config.AddUniqueInt(exc.TVDBIDs, *item.TVDBID)
+		if len(exc.TVDBIDs) > before {
+			changes = append(changes, fmt.Sprintf("tvdb_id=%d", *item.TVDBID))
+		}
+	}
+	if item.IMDBID != "" {
+		before := len(exc.IMDBIDs)
+		exc.IMDBIDs = config.AddUniqueString(exc.IMDBIDs, item.IMDBID)
+		if len(exc.IMDBIDs) > before {
+			changes = append(changes, fmt.Sprintf("imdb_id=%s", item.IMDBID))
+		}
+	}
+	if item.Title != "" {
+		before := len(exc.Titles)
+		exc.Titles = config.AddUniqueString(exc.Titles, item.Title)
+		if len(exc.Titles) > before {
+			changes = append(changes, fmt.Sprintf("title=%q", item.Title))
+		}
+	}
+	if item.Path != "" {
+		before := len(exc.PathPrefixes)
+		exc.PathPrefixes = config.AddUniqueString(exc.PathPrefixes, item.Path)
+		if len(exc.PathPrefixes) > before {
+			changes = append(changes, fmt.Sprintf("path=%q", item.Path))
+		}
+	}
+	return changes
+}
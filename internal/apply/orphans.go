@@ -0,0 +1,51 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/scan"
+)
+
+// deleteOrphan removes an untracked folder or file from disk. There is no
+// Arr entry to go through, so this is the only destructive path that works
+// on the filesystem directly.
+func (e *Executor) deleteOrphan(ctx context.Context, item report.Item, result *Result) error {
+	local, err := e.checkOrphan(ctx, item)
+	if err != nil {
+		return err
+	}
+	logging.L().Debug().Str("title", item.Title).Str("path", local).Msg("Deleting orphan")
+	if err := os.RemoveAll(local); err != nil {
+		return err
+	}
+	result.BytesFreed = item.Reclaimable()
+	return nil
+}
+
+// checkOrphan returns the host path of an orphan once it is confirmed to
+// still sit directly inside a root folder without being tracked. A report
+// edited by hand, a changed path mapping or a service that started tracking
+// the folder since the scan must never lead to removing files it owns. The
+// tracked library is fetched once per executor.
+func (e *Executor) checkOrphan(ctx context.Context, item report.Item) (string, error) {
+	local := filepath.Clean(e.localPath(item))
+	if local == "." || local == "/" || filepath.Dir(local) == "/" {
+		return "", fmt.Errorf("refusing to delete %q", local)
+	}
+	if e.orphans == nil {
+		guard, err := scan.NewOrphanGuard(ctx, e.arr, e.paths)
+		if err != nil {
+			return "", err
+		}
+		e.orphans = guard
+	}
+	if err := e.orphans.Check(local); err != nil {
+		return "", fmt.Errorf("refusing to remove orphan: %w", err)
+	}
+	return local, nil
+}
@@ -0,0 +1,127 @@
+package apply
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+type Progress struct {
+	Report    string                  `json:"report,omitempty"`
+	StartedAt time.Time               `json:"started_at"`
+	UpdatedAt time.Time               `json:"updated_at"`
+	Items     map[string]ItemProgress `json:"items"`
+
+	path string
+}
+
+type ItemProgress struct {
+	Title     string    `json:"title"`
+	Action    string    `json:"action"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ProgressPath(reportPath string) string {
+	return reportPath + ".progress.json"
+}
+
+func newProgress(path string, reportPath string) *Progress {
+	now := time.Now().UTC()
+	return &Progress{
+		Report:    reportPath,
+		StartedAt: now,
+		UpdatedAt: now,
+		Items:     map[string]ItemProgress{},
+		path:      path,
+	}
+}
+
+// LoadProgress reads a progress file. It returns nil when the file does not
+// exist.
+func LoadProgress(path string) (*Progress, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read progress: %w", err)
+	}
+	var progress Progress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("parse progress %s: %w", path, err)
+	}
+	if progress.Items == nil {
+		progress.Items = map[string]ItemProgress{}
+	}
+	progress.path = path
+	return &progress, nil
+}
+
+func (p *Progress) status(key string) string {
+	if p == nil {
+		return ""
+	}
+	return p.Items[key].Status
+}
+
+func (p *Progress) mark(key string, title string, action string, status string, reason string) error {
+	if p == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	p.Items[key] = ItemProgress{
+		Title:     title,
+		Action:    action,
+		Status:    status,
+		Reason:    reason,
+		UpdatedAt: now,
+	}
+	p.UpdatedAt = now
+	return p.save()
+}
+
+func (p *Progress) save() error {
+	payload, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal progress: %w", err)
+	}
+	payload = append(payload, '\n')
+	tmp, err := os.CreateTemp(filepath.Dir(p.path), filepath.Base(p.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write progress: %w", err)
+	}
+	if _, err := tmp.Write(payload); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write progress: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write progress: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p.path); err != nil {
+		return fmt.Errorf("write progress: %w", err)
+	}
+	return nil
+}
+
+func (p *Progress) remove() error {
+	if p == nil {
+		return nil
+	}
+	if err := os.Remove(p.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove progress: %w", err)
+	}
+	return nil
+}
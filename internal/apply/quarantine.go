@@ -0,0 +1,225 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"go-unraid-clean/internal/arr"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/journal"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/quarantine"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/state"
+)
+
+func (e *Executor) quarantine(ctx context.Context, item report.Item, result *Result) error {
+	store, err := quarantine.NewStore(state.FromConfig(*e.cfg).QuarantinePath())
+	if err != nil {
+		return err
+	}
+
+	entry := quarantine.Entry{
+		Type:          item.Type,
+		Title:         item.Title,
+		Instance:      item.Instance,
+		RadarrID:      item.RadarrID,
+		SonarrID:      item.SonarrID,
+		TMDBID:        item.TMDBID,
+		TVDBID:        item.TVDBID,
+		IMDBID:        item.IMDBID,
+		OriginalPath:  item.Path,
+		LocalPath:     e.localPath(item),
+		SizeBytes:     item.SizeBytes,
+		QuarantinedAt: time.Now().UTC(),
+	}
+
+	var remove func() error
+	switch item.Type {
+	case "movie":
+		if item.RadarrID == nil {
+			return fmt.Errorf("missing radarr_id")
+		}
+		radarr, err := e.arr.RadarrFor(item.Instance)
+		if err != nil {
+			return err
+		}
+		entry.ID = fmt.Sprintf("%s-%d-%s", e.quarantinePrefix("movie", radarr.Name), *item.RadarrID, entry.QuarantinedAt.Format("20060102-150405"))
+		entry.Resource, err = radarr.MovieResource(ctx, *item.RadarrID)
+		if err != nil {
+			return err
+		}
+		remove = func() error { return radarr.DeleteMovie(ctx, *item.RadarrID, false) }
+	case "series":
+		if item.SonarrID == nil {
+			return fmt.Errorf("missing sonarr_id")
+		}
+		sonarr, err := e.arr.SonarrFor(item.Instance)
+		if err != nil {
+			return err
+		}
+		entry.ID = fmt.Sprintf("%s-%d-%s", e.quarantinePrefix("series", sonarr.Name), *item.SonarrID, entry.QuarantinedAt.Format("20060102-150405"))
+		entry.Resource, err = sonarr.SeriesResource(ctx, *item.SonarrID)
+		if err != nil {
+			return err
+		}
+		remove = func() error { return sonarr.DeleteSeries(ctx, *item.SonarrID, false) }
+	case "orphan":
+		if _, err := e.checkOrphan(ctx, item); err != nil {
+			return err
+		}
+		hash := fnv.New32a()
+		hash.Write([]byte(entry.LocalPath))
+		entry.ID = fmt.Sprintf("orphan-%08x-%s", hash.Sum32(), entry.QuarantinedAt.Format("20060102-150405"))
+		remove = func() error { return nil }
+	default:
+		return fmt.Errorf("unsupported item type: %s", item.Type)
+	}
+
+	logging.L().Debug().Str("title", item.Title).Str("path", entry.FilesPath()).Msg("Moving item to quarantine")
+	entry, err = store.Put(entry)
+	if err != nil {
+		return err
+	}
+	if err := remove(); err != nil {
+		if rollbackErr := store.MoveBack(entry); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		_ = store.Purge(entry)
+		return err
+	}
+	result.Destination = entry.StoredPath
+	logging.L().Info().Str("title", item.Title).Str("id", entry.ID).Str("stored_path", entry.StoredPath).Msg("Quarantined item")
+	return nil
+}
+
+// quarantinePrefix starts quarantine IDs with the item type, adding the
+// instance name for every instance but the first so IDs stay unique.
+func (e *Executor) quarantinePrefix(itemType string, instance string) string {
+	first := e.cfg.Radarr.First()
+	if itemType != "movie" {
+		first = e.cfg.Sonarr.First()
+	}
+	if instance == first {
+		return itemType
+	}
+	return itemType + "-" + instance
+}
+
+func PurgeQuarantine(cfg config.Config, olderThan time.Duration, dryRun bool) ([]quarantine.Entry, error) {
+	store, err := quarantine.NewStore(state.FromConfig(cfg).QuarantinePath())
+	if err != nil {
+		return nil, err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	jr := journal.Open(state.FromConfig(cfg).JournalPath(), journal.Origin{Command: "purge"})
+	cutoff := time.Now().UTC().Add(-olderThan)
+	purged := []quarantine.Entry{}
+	for _, entry := range entries {
+		if entry.QuarantinedAt.After(cutoff) {
+			continue
+		}
+		purged = append(purged, entry)
+		if dryRun {
+			continue
+		}
+		err := store.Purge(entry)
+		recordQuarantine(jr, "purge", entry, err)
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// RestoreQuarantine moves an item's files back and re-adds it to its Arr. The
+// entry is only removed from the store once the re-add succeeded; otherwise
+// the files go back into quarantine so the restore can be retried.
+func RestoreQuarantine(ctx context.Context, cfg config.Config, id string) (quarantine.Entry, error) {
+	store, err := quarantine.NewStore(state.FromConfig(cfg).QuarantinePath())
+	if err != nil {
+		return quarantine.Entry{}, err
+	}
+	entry, err := store.Get(id)
+	if err != nil {
+		return entry, err
+	}
+	jr := journal.Open(state.FromConfig(cfg).JournalPath(), journal.Origin{Command: "restore"})
+
+	if err := store.MoveBack(entry); err != nil {
+		recordQuarantine(jr, "restore", entry, err)
+		return entry, err
+	}
+	if err := readdToArr(ctx, cfg, entry); err != nil {
+		if returnErr := store.Return(entry); returnErr != nil {
+			err = fmt.Errorf("re-adding failed: %w; files left at %s (%v)", err, entry.FilesPath(), returnErr)
+		} else {
+			err = fmt.Errorf("re-adding failed, files kept in quarantine: %w", err)
+		}
+		recordQuarantine(jr, "restore", entry, err)
+		return entry, err
+	}
+	err = store.Purge(entry)
+	recordQuarantine(jr, "restore", entry, err)
+	return entry, err
+}
+
+func readdToArr(ctx context.Context, cfg config.Config, entry quarantine.Entry) error {
+	if entry.Type == "orphan" {
+		return nil
+	}
+	if len(entry.Resource) == 0 {
+		return fmt.Errorf("no saved resource for %s", entry.ID)
+	}
+	arrs, err := arr.New(cfg)
+	if err != nil {
+		return err
+	}
+	switch entry.Type {
+	case "movie":
+		radarr, err := arrs.RadarrFor(entry.Instance)
+		if err != nil {
+			return err
+		}
+		return radarr.AddMovie(ctx, entry.Resource, entry.OriginalPath)
+	case "series":
+		sonarr, err := arrs.SonarrFor(entry.Instance)
+		if err != nil {
+			return err
+		}
+		return sonarr.AddSeries(ctx, entry.Resource, entry.OriginalPath)
+	default:
+		return fmt.Errorf("unsupported item type: %s", entry.Type)
+	}
+}
+
+func recordQuarantine(jr *journal.Journal, action string, entry quarantine.Entry, actionErr error) {
+	record := journal.Entry{
+		Action:      action,
+		Type:        entry.Type,
+		Title:       entry.Title,
+		Instance:    entry.Instance,
+		RadarrID:    entry.RadarrID,
+		SonarrID:    entry.SonarrID,
+		TMDBID:      entry.TMDBID,
+		TVDBID:      entry.TVDBID,
+		IMDBID:      entry.IMDBID,
+		Path:        entry.OriginalPath,
+		Destination: entry.StoredPath,
+		Success:     actionErr == nil,
+	}
+	if action == "purge" && actionErr == nil {
+		record.BytesFreed = entry.SizeBytes
+	}
+	if actionErr != nil {
+		record.Error = actionErr.Error()
+	}
+	if err := jr.Record(record); err != nil {
+		logging.L().Error().Err(err).Str("title", entry.Title).Msg("Failed to write journal entry")
+	}
+}
@@ -0,0 +1,44 @@
+package apply
+
+import (
+	"context"
+
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+// clearRequest removes the Overseerr media entry for a deleted movie or
+// series so it can be requested again. Failures are logged, not returned,
+// because the delete itself already succeeded.
+func (e *Executor) clearRequest(ctx context.Context, item report.Item) {
+	if e.overseerr == nil || (item.Type != "movie" && item.Type != "series") {
+		return
+	}
+	log := logging.L()
+	if e.requests == nil {
+		requests, err := e.overseerr.Requests(ctx)
+		if err != nil {
+			log.Warn().Err(err).Str("title", item.Title).Msg("Unable to load Overseerr requests")
+			return
+		}
+		e.requests = requests
+	}
+	for _, request := range e.requests {
+		matched := false
+		switch item.Type {
+		case "movie":
+			matched = request.Type == "movie" && item.TMDBID != nil && request.Media.TMDBID == *item.TMDBID
+		case "series":
+			matched = request.Type == "tv" && item.TVDBID != nil && request.Media.TVDBID == *item.TVDBID
+		}
+		if !matched || request.Media.ID == 0 {
+			continue
+		}
+		if err := e.overseerr.DeleteMedia(ctx, request.Media.ID); err != nil {
+			log.Warn().Err(err).Str("title", item.Title).Msg("Unable to clear Overseerr request")
+			return
+		}
+		log.Info().Str("title", item.Title).Int("media_id", request.Media.ID).Msg("Cleared Overseerr request")
+		return
+	}
+}
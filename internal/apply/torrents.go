@@ -0,0 +1,66 @@
+package apply
+
+import (
+	"context"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+// torrentHashes collects the torrents to consider for removal before the
+// item is deleted, because Radarr and Sonarr drop history with the item.
+func (e *Executor) torrentHashes(ctx context.Context, item report.Item) []string {
+	if e.torrents == nil {
+		return nil
+	}
+	if len(item.Torrents) > 0 {
+		hashes := make([]string, 0, len(item.Torrents))
+		for _, t := range item.Torrents {
+			hashes = append(hashes, t.Hash)
+		}
+		return hashes
+	}
+	records, err := e.arr.History(ctx, item)
+	if err != nil {
+		logging.L().Warn().Err(err).Str("title", item.Title).Msg("Unable to load download history")
+		return nil
+	}
+	return clients.TorrentHashes(records)
+}
+
+// removeTorrents removes torrents and their data once the seeding goal is
+// met, re-checking ratio and seed time against the client. Torrents below
+// the goal keep seeding; failures are logged because the delete succeeded.
+func (e *Executor) removeTorrents(ctx context.Context, item report.Item, hashes []string, result *Result) {
+	if e.torrents == nil || len(hashes) == 0 {
+		return
+	}
+	log := logging.L()
+	list, err := e.torrents.Torrents(ctx)
+	if err != nil {
+		log.Warn().Err(err).Str("title", item.Title).Msg("Unable to load torrents")
+		return
+	}
+	live := make(map[string]clients.Torrent, len(list))
+	for _, t := range list {
+		live[t.Hash] = t
+	}
+	for _, hash := range hashes {
+		t, ok := live[hash]
+		if !ok {
+			continue
+		}
+		if !e.cfg.Torrent.GoalMet(t.Ratio, t.SeedingSeconds) {
+			log.Info().Str("title", item.Title).Str("torrent", t.Name).Float64("ratio", t.Ratio).
+				Msg("Torrent has not reached its seeding goal; leaving it in the client")
+			continue
+		}
+		if err := e.torrents.Remove(ctx, hash, true); err != nil {
+			log.Warn().Err(err).Str("title", item.Title).Str("torrent", t.Name).Msg("Unable to remove torrent")
+			continue
+		}
+		log.Info().Str("title", item.Title).Str("torrent", t.Name).Msg("Removed torrent and data")
+		result.Torrents = append(result.Torrents, hash)
+	}
+}
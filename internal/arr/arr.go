@@ -0,0 +1,95 @@
+// Package arr holds a client for every configured Radarr and Sonarr instance
+// and routes report items to the instance they came from.
+package arr
+
+import (
+	"context"
+	"fmt"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/report"
+)
+
+type Radarr struct {
+	Name string
+	*clients.RadarrClient
+}
+
+type Sonarr struct {
+	Name string
+	*clients.SonarrClient
+}
+
+// Clients lists the instances of each service in config order.
+type Clients struct {
+	Radarr []Radarr
+	Sonarr []Sonarr
+}
+
+func New(cfg config.Config) (*Clients, error) {
+	out := &Clients{}
+	for _, inst := range cfg.Radarr {
+		client, err := clients.NewRadarrClient(inst.BaseURL, inst.APIKey)
+		if err != nil {
+			return nil, fmt.Errorf("radarr %s: %w", inst.Name, err)
+		}
+		out.Radarr = append(out.Radarr, Radarr{Name: inst.Name, RadarrClient: client})
+	}
+	for _, inst := range cfg.Sonarr {
+		client, err := clients.NewSonarrClient(inst.BaseURL, inst.APIKey)
+		if err != nil {
+			return nil, fmt.Errorf("sonarr %s: %w", inst.Name, err)
+		}
+		out.Sonarr = append(out.Sonarr, Sonarr{Name: inst.Name, SonarrClient: client})
+	}
+	return out, nil
+}
+
+// RadarrFor returns the named Radarr instance. An empty name selects the
+// first instance, for reports written before items carried an instance.
+func (c *Clients) RadarrFor(name string) (Radarr, error) {
+	for _, r := range c.Radarr {
+		if name == "" || r.Name == name {
+			return r, nil
+		}
+	}
+	return Radarr{}, fmt.Errorf("radarr instance %q is not configured", name)
+}
+
+// SonarrFor returns the named Sonarr instance. An empty name selects the
+// first instance, for reports written before items carried an instance.
+func (c *Clients) SonarrFor(name string) (Sonarr, error) {
+	for _, s := range c.Sonarr {
+		if name == "" || s.Name == name {
+			return s, nil
+		}
+	}
+	return Sonarr{}, fmt.Errorf("sonarr instance %q is not configured", name)
+}
+
+// History returns the download history of a report item from the instance
+// it belongs to; items of other types have none.
+func (c *Clients) History(ctx context.Context, item report.Item) ([]clients.ArrHistoryRecord, error) {
+	switch {
+	case item.Type == "movie" && item.RadarrID != nil:
+		radarr, err := c.RadarrFor(item.Instance)
+		if err != nil {
+			return nil, err
+		}
+		return radarr.MovieHistory(ctx, *item.RadarrID)
+	case item.Type == "series" && item.SonarrID != nil:
+		sonarr, err := c.SonarrFor(item.Instance)
+		if err != nil {
+			return nil, err
+		}
+		return sonarr.SeriesHistory(ctx, *item.SonarrID, nil)
+	case item.Type == "season" && item.SonarrID != nil:
+		sonarr, err := c.SonarrFor(item.Instance)
+		if err != nil {
+			return nil, err
+		}
+		return sonarr.SeriesHistory(ctx, *item.SonarrID, item.SeasonNumber)
+	}
+	return nil, nil
+}
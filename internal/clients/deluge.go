@@ -0,0 +1,127 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DelugeClient talks to the Deluge Web UI JSON-RPC endpoint, which must be
+// connected to a daemon.
+type DelugeClient struct {
+	http     *HTTPClient
+	password string
+	loggedIn bool
+	nextID   int
+}
+
+type delugeTorrent struct {
+	Name        string  `json:"name"`
+	SavePath    string  `json:"save_path"`
+	State       string  `json:"state"`
+	Progress    float64 `json:"progress"`
+	Ratio       float64 `json:"ratio"`
+	SeedingTime int64   `json:"seeding_time"`
+	TotalSize   int64   `json:"total_size"`
+}
+
+func (c *DelugeClient) Name() string {
+	return TorrentClientDeluge
+}
+
+func (c *DelugeClient) call(ctx context.Context, method string, params []any, out any) error {
+	c.nextID++
+	payload, err := json.Marshal(map[string]any{"method": method, "params": params, "id": c.nextID})
+	if err != nil {
+		return fmt.Errorf("deluge %s: encode: %w", method, err)
+	}
+	req, err := http.NewRequest(http.MethodPost, c.http.Resolve("json"), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(ctx, c.http.Client, req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := readBody(resp)
+		return fmt.Errorf("deluge %s: status %d: %s", method, resp.StatusCode, string(body))
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := decodeJSONBody(resp, &envelope); err != nil {
+		return err
+	}
+	if envelope.Error != nil {
+		return fmt.Errorf("deluge %s: %s", method, envelope.Error.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("deluge %s: decode: %w", method, err)
+	}
+	return nil
+}
+
+func (c *DelugeClient) login(ctx context.Context) error {
+	if c.loggedIn {
+		return nil
+	}
+	var ok bool
+	if err := c.call(ctx, "auth.login", []any{c.password}, &ok); err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("deluge login: invalid password")
+	}
+	c.loggedIn = true
+	return nil
+}
+
+func (c *DelugeClient) Torrents(ctx context.Context) ([]Torrent, error) {
+	if err := c.login(ctx); err != nil {
+		return nil, err
+	}
+	fields := []string{"name", "save_path", "state", "progress", "ratio", "seeding_time", "total_size"}
+	var result map[string]delugeTorrent
+	if err := c.call(ctx, "core.get_torrents_status", []any{map[string]any{}, fields}, &result); err != nil {
+		return nil, err
+	}
+	out := make([]Torrent, 0, len(result))
+	for hash, t := range result {
+		ratio := t.Ratio
+		if ratio < 0 {
+			ratio = 0
+		}
+		out = append(out, Torrent{
+			Hash:           strings.ToLower(hash),
+			Name:           t.Name,
+			SavePath:       t.SavePath,
+			State:          strings.ToLower(t.State),
+			Seeding:        t.State == "Seeding",
+			Complete:       t.Progress >= 100,
+			Ratio:          ratio,
+			SeedingSeconds: t.SeedingTime,
+			SizeBytes:      t.TotalSize,
+		})
+	}
+	return out, nil
+}
+
+func (c *DelugeClient) Remove(ctx context.Context, hash string, deleteData bool) error {
+	if err := c.login(ctx); err != nil {
+		return err
+	}
+	return c.call(ctx, "core.remove_torrent", []any{hash, deleteData}, nil)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,6 +17,8 @@ import (
 	"github.com/rs/zerolog"
 )
 
+var ErrNotFound = errors.New("not found")
+
 type HTTPClient struct {
 	BaseURL *url.URL
 	APIKey  string
@@ -111,3 +114,53 @@ func truncateBody(body string) string {
 	}
 	return body[:limit] + "...(truncated)"
 }
+
+func getRaw(ctx context.Context, c *HTTPClient, path string, label string) (json.RawMessage, error) {
+	req, err := http.NewRequest(http.MethodGet, c.Resolve(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Api-Key", c.APIKey)
+
+	resp, err := doRequest(ctx, c.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", label, ErrNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := readBody(resp)
+		return nil, fmt.Errorf("%s: status %d: %s", label, resp.StatusCode, string(body))
+	}
+	body, err := readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(body), nil
+}
+
+func sendJSON(ctx context.Context, c *HTTPClient, method string, path string, payload any, label string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s: encode: %w", label, err)
+	}
+	req, err := http.NewRequest(method, c.Resolve(path), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Api-Key", c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRequest(ctx, c.Client, req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := readBody(resp)
+		return fmt.Errorf("%s: status %d: %s", label, resp.StatusCode, string(respBody))
+	}
+	resp.Body.Close()
+	return nil
+}
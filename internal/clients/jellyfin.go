@@ -0,0 +1,207 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// JellyfinClient talks to Jellyfin or Emby, which share the same REST API.
+type JellyfinClient struct {
+	http  *HTTPClient
+	label string
+}
+
+type JellyfinUser struct {
+	ID   string `json:"Id"`
+	Name string `json:"Name"`
+}
+
+type JellyfinItem struct {
+	ID                string            `json:"Id"`
+	Name              string            `json:"Name"`
+	Type              string            `json:"Type"`
+	ProductionYear    int               `json:"ProductionYear"`
+	ProviderIDs       map[string]string `json:"ProviderIds"`
+	SeriesID          string            `json:"SeriesId"`
+	SeriesName        string            `json:"SeriesName"`
+	ParentIndexNumber int               `json:"ParentIndexNumber"`
+	IndexNumber       int               `json:"IndexNumber"`
+	RunTimeTicks      int64             `json:"RunTimeTicks"`
+	UserData          *JellyfinUserData `json:"UserData"`
+}
+
+type JellyfinUserData struct {
+	Played                bool   `json:"Played"`
+	PlayCount             int    `json:"PlayCount"`
+	LastPlayedDate        string `json:"LastPlayedDate"`
+	PlaybackPositionTicks int64  `json:"PlaybackPositionTicks"`
+}
+
+// JellyfinPlay is one row from the Playback Reporting plugin.
+type JellyfinPlay struct {
+	Date     string
+	User     string
+	ItemID   string
+	ItemType string
+	ItemName string
+	Seconds  int64
+}
+
+type jellyfinItemsPage struct {
+	Items            []JellyfinItem `json:"Items"`
+	TotalRecordCount int            `json:"TotalRecordCount"`
+}
+
+func NewJellyfinClient(baseURL, apiKey string) (*JellyfinClient, error) {
+	hc, err := NewHTTPClient(baseURL, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &JellyfinClient{http: hc, label: "jellyfin"}, nil
+}
+
+func NewEmbyClient(baseURL, apiKey string) (*JellyfinClient, error) {
+	hc, err := NewHTTPClient(baseURL, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &JellyfinClient{http: hc, label: "emby"}, nil
+}
+
+func (c *JellyfinClient) Users(ctx context.Context) ([]JellyfinUser, error) {
+	var out []JellyfinUser
+	if err := c.getJSON(ctx, "Users", nil, &out, c.label+" users"); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Items lists library items of the given types (e.g. "Movie,Series,Episode")
+// with provider IDs.
+func (c *JellyfinClient) Items(ctx context.Context, types string) ([]JellyfinItem, error) {
+	query := url.Values{}
+	query.Set("Recursive", "true")
+	query.Set("IncludeItemTypes", types)
+	query.Set("Fields", "ProviderIds")
+	return c.pagedItems(ctx, "Items", query, c.label+" items")
+}
+
+// UserItems lists a user's movies and episodes matching filter ("IsPlayed" or
+// "IsResumable") with their play state.
+func (c *JellyfinClient) UserItems(ctx context.Context, userID string, filter string) ([]JellyfinItem, error) {
+	query := url.Values{}
+	query.Set("Recursive", "true")
+	query.Set("IncludeItemTypes", "Movie,Episode")
+	query.Set("Filters", filter)
+	query.Set("Fields", "ProviderIds")
+	query.Set("EnableUserData", "true")
+	label := fmt.Sprintf("%s user %s items", c.label, userID)
+	return c.pagedItems(ctx, fmt.Sprintf("Users/%s/Items", userID), query, label)
+}
+
+// PlaybackActivity queries the Playback Reporting plugin. It returns
+// ErrNotFound when the plugin is not installed.
+func (c *JellyfinClient) PlaybackActivity(ctx context.Context) ([]JellyfinPlay, error) {
+	label := c.label + " playback reporting"
+	payload := map[string]any{
+		"CustomQueryString": "SELECT DateCreated, UserId, ItemId, ItemType, ItemName, PlayDuration FROM PlaybackActivity",
+		"ReplaceUserId":     true,
+	}
+	var out struct {
+		Results [][]any `json:"results"`
+	}
+	if err := c.postJSON(ctx, "user_usage_stats/submit_custom_query", payload, &out, label); err != nil {
+		return nil, err
+	}
+	plays := make([]JellyfinPlay, 0, len(out.Results))
+	for _, row := range out.Results {
+		if len(row) < 6 {
+			continue
+		}
+		plays = append(plays, JellyfinPlay{
+			Date:     fmt.Sprint(row[0]),
+			User:     fmt.Sprint(row[1]),
+			ItemID:   fmt.Sprint(row[2]),
+			ItemType: fmt.Sprint(row[3]),
+			ItemName: fmt.Sprint(row[4]),
+			Seconds:  int64(coerceInt(row[5])),
+		})
+	}
+	return plays, nil
+}
+
+func (c *JellyfinClient) pagedItems(ctx context.Context, path string, query url.Values, label string) ([]JellyfinItem, error) {
+	const pageSize = 1000
+	all := []JellyfinItem{}
+	start := 0
+	for {
+		page := url.Values{}
+		for key, values := range query {
+			page[key] = values
+		}
+		page.Set("StartIndex", strconv.Itoa(start))
+		page.Set("Limit", strconv.Itoa(pageSize))
+		var out jellyfinItemsPage
+		if err := c.getJSON(ctx, path, page, &out, label); err != nil {
+			return nil, err
+		}
+		all = append(all, out.Items...)
+		if len(out.Items) == 0 || start+len(out.Items) >= out.TotalRecordCount {
+			break
+		}
+		start += len(out.Items)
+	}
+	return all, nil
+}
+
+func (c *JellyfinClient) getJSON(ctx context.Context, path string, query url.Values, out any, label string) error {
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, c.http.Resolve(target), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, out, label)
+}
+
+func (c *JellyfinClient) postJSON(ctx context.Context, path string, payload any, out any, label string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s: encode: %w", label, err)
+	}
+	req, err := http.NewRequest(http.MethodPost, c.http.Resolve(path), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(ctx, req, out, label)
+}
+
+func (c *JellyfinClient) do(ctx context.Context, req *http.Request, out any, label string) error {
+	req.Header.Set("X-Emby-Token", c.http.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := doRequest(ctx, c.http.Client, req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return fmt.Errorf("%s: %w", label, ErrNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := readBody(resp)
+		return fmt.Errorf("%s: status %d: %s", label, resp.StatusCode, string(body))
+	}
+	if err := decodeJSONBody(resp, out); err != nil {
+		return fmt.Errorf("%s: %w", label, err)
+	}
+	return nil
+}
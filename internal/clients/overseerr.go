@@ -0,0 +1,108 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// OverseerrClient talks to Overseerr or Jellyseerr, which share the same API.
+type OverseerrClient struct {
+	http *HTTPClient
+}
+
+type OverseerrUser struct {
+	ID               int    `json:"id"`
+	DisplayName      string `json:"displayName"`
+	Username         string `json:"username"`
+	PlexUsername     string `json:"plexUsername"`
+	JellyfinUsername string `json:"jellyfinUsername"`
+	Email            string `json:"email"`
+}
+
+type OverseerrRequest struct {
+	ID        int    `json:"id"`
+	Status    int    `json:"status"`
+	Type      string `json:"type"`
+	CreatedAt string `json:"createdAt"`
+	Media     struct {
+		ID        int    `json:"id"`
+		TMDBID    int    `json:"tmdbId"`
+		TVDBID    int    `json:"tvdbId"`
+		MediaType string `json:"mediaType"`
+	} `json:"media"`
+	RequestedBy OverseerrUser `json:"requestedBy"`
+}
+
+func NewOverseerrClient(baseURL, apiKey string) (*OverseerrClient, error) {
+	hc, err := NewHTTPClient(baseURL, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &OverseerrClient{http: hc}, nil
+}
+
+func (c *OverseerrClient) Requests(ctx context.Context) ([]OverseerrRequest, error) {
+	const pageSize = 100
+	all := []OverseerrRequest{}
+	skip := 0
+	for {
+		query := url.Values{}
+		query.Set("take", strconv.Itoa(pageSize))
+		query.Set("skip", strconv.Itoa(skip))
+		query.Set("filter", "all")
+		req, err := http.NewRequest(http.MethodGet, c.http.Resolve("api/v1/request?"+query.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Api-Key", c.http.APIKey)
+
+		resp, err := doRequest(ctx, c.http.Client, req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := readBody(resp)
+			return nil, fmt.Errorf("overseerr requests: status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var page struct {
+			PageInfo struct {
+				Results int `json:"results"`
+			} `json:"pageInfo"`
+			Results []OverseerrRequest `json:"results"`
+		}
+		if err := decodeJSONBody(resp, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Results...)
+		if len(page.Results) == 0 || skip+len(page.Results) >= page.PageInfo.Results {
+			break
+		}
+		skip += len(page.Results)
+	}
+	return all, nil
+}
+
+// DeleteMedia removes the media entry and its requests so the title can be
+// requested again.
+func (c *OverseerrClient) DeleteMedia(ctx context.Context, mediaID int) error {
+	req, err := http.NewRequest(http.MethodDelete, c.http.Resolve(fmt.Sprintf("api/v1/media/%d", mediaID)), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Api-Key", c.http.APIKey)
+
+	resp, err := doRequest(ctx, c.http.Client, req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := readBody(resp)
+		return fmt.Errorf("overseerr delete media %d: status %d: %s", mediaID, resp.StatusCode, string(body))
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,257 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type PlexClient struct {
+	http *HTTPClient
+}
+
+type PlexSection struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+}
+
+type PlexGuid struct {
+	ID string `json:"id"`
+}
+
+type PlexMetadata struct {
+	RatingKey            string     `json:"ratingKey"`
+	Key                  string     `json:"key"`
+	Type                 string     `json:"type"`
+	Title                string     `json:"title"`
+	Year                 int        `json:"year"`
+	Guid                 string     `json:"guid"`
+	Guids                []PlexGuid `json:"Guid"`
+	Duration             int64      `json:"duration"`
+	ViewCount            int        `json:"viewCount"`
+	LastViewedAt         int64      `json:"lastViewedAt"`
+	GrandparentRatingKey string     `json:"grandparentRatingKey"`
+	GrandparentKey       string     `json:"grandparentKey"`
+	GrandparentTitle     string     `json:"grandparentTitle"`
+	ParentIndex          int        `json:"parentIndex"`
+	Index                int        `json:"index"`
+	ViewedAt             int64      `json:"viewedAt"`
+	AccountID            int        `json:"accountID"`
+	User                 *struct {
+		Title string `json:"title"`
+	} `json:"User"`
+}
+
+type PlexAccount struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type plexContainer struct {
+	MediaContainer struct {
+		MachineIdentifier string         `json:"machineIdentifier"`
+		Size              int            `json:"size"`
+		TotalSize         int            `json:"totalSize"`
+		Metadata          []PlexMetadata `json:"Metadata"`
+		Directory         []PlexSection  `json:"Directory"`
+		Account           []PlexAccount  `json:"Account"`
+	} `json:"MediaContainer"`
+}
+
+func NewPlexClient(baseURL, token string) (*PlexClient, error) {
+	hc, err := NewHTTPClient(baseURL, token)
+	if err != nil {
+		return nil, err
+	}
+	return &PlexClient{http: hc}, nil
+}
+
+func (c *PlexClient) Sections(ctx context.Context) ([]PlexSection, error) {
+	out, err := c.get(ctx, "library/sections", nil, "plex sections")
+	if err != nil {
+		return nil, err
+	}
+	return out.MediaContainer.Directory, nil
+}
+
+// SectionItems lists a library section with external GUIDs included. itemType
+// selects a Plex metadata type (1 movie, 2 show, 4 episode); 0 uses the
+// section default.
+func (c *PlexClient) SectionItems(ctx context.Context, sectionKey string, itemType int) ([]PlexMetadata, error) {
+	query := url.Values{}
+	query.Set("includeGuids", "1")
+	if itemType > 0 {
+		query.Set("type", strconv.Itoa(itemType))
+	}
+	label := fmt.Sprintf("plex section %s", sectionKey)
+	return c.paged(ctx, fmt.Sprintf("library/sections/%s/all", sectionKey), query, label)
+}
+
+func (c *PlexClient) History(ctx context.Context) ([]PlexMetadata, error) {
+	query := url.Values{}
+	query.Set("sort", "viewedAt:desc")
+	return c.paged(ctx, "status/sessions/history/all", query, "plex history")
+}
+
+// OnDeck lists the token owner's Continue Watching items.
+func (c *PlexClient) OnDeck(ctx context.Context) ([]PlexMetadata, error) {
+	query := url.Values{}
+	query.Set("includeGuids", "1")
+	out, err := c.get(ctx, "library/onDeck", query, "plex on deck")
+	if err != nil {
+		return nil, err
+	}
+	return out.MediaContainer.Metadata, nil
+}
+
+// Sessions lists items currently being played on the server.
+func (c *PlexClient) Sessions(ctx context.Context) ([]PlexMetadata, error) {
+	out, err := c.get(ctx, "status/sessions", nil, "plex sessions")
+	if err != nil {
+		return nil, err
+	}
+	return out.MediaContainer.Metadata, nil
+}
+
+func (c *PlexClient) Accounts(ctx context.Context) ([]PlexAccount, error) {
+	out, err := c.get(ctx, "accounts", nil, "plex accounts")
+	if err != nil {
+		return nil, err
+	}
+	return out.MediaContainer.Account, nil
+}
+
+func (c *PlexClient) paged(ctx context.Context, path string, query url.Values, label string) ([]PlexMetadata, error) {
+	const pageSize = 500
+	all := []PlexMetadata{}
+	start := 0
+	for {
+		page := url.Values{}
+		for key, values := range query {
+			page[key] = values
+		}
+		page.Set("X-Plex-Container-Start", strconv.Itoa(start))
+		page.Set("X-Plex-Container-Size", strconv.Itoa(pageSize))
+		out, err := c.get(ctx, path, page, label)
+		if err != nil {
+			return nil, err
+		}
+		items := out.MediaContainer.Metadata
+		all = append(all, items...)
+		total := out.MediaContainer.TotalSize
+		if len(items) == 0 || (total > 0 && start+len(items) >= total) || (total == 0 && len(items) < pageSize) {
+			break
+		}
+		start += len(items)
+	}
+	return all, nil
+}
+
+// MachineIdentifier returns the server's ID, which collection item URIs
+// refer to.
+func (c *PlexClient) MachineIdentifier(ctx context.Context) (string, error) {
+	out, err := c.get(ctx, "identity", nil, "plex identity")
+	if err != nil {
+		return "", err
+	}
+	return out.MediaContainer.MachineIdentifier, nil
+}
+
+// Collections lists the collections of a library section.
+func (c *PlexClient) Collections(ctx context.Context, sectionKey string) ([]PlexMetadata, error) {
+	out, err := c.get(ctx, fmt.Sprintf("library/sections/%s/collections", sectionKey), nil, "plex collections")
+	if err != nil {
+		return nil, err
+	}
+	return out.MediaContainer.Metadata, nil
+}
+
+func (c *PlexClient) CollectionItems(ctx context.Context, collectionKey string) ([]PlexMetadata, error) {
+	out, err := c.get(ctx, fmt.Sprintf("library/collections/%s/children", collectionKey), nil, "plex collection items")
+	if err != nil {
+		return nil, err
+	}
+	return out.MediaContainer.Metadata, nil
+}
+
+// CreateCollection creates a collection in a library section holding the
+// given items. itemType is the Plex metadata type of the items (1 movie,
+// 2 show).
+func (c *PlexClient) CreateCollection(ctx context.Context, machineID string, sectionKey string, itemType int, title string, ratingKeys []string) (PlexMetadata, error) {
+	query := url.Values{}
+	query.Set("type", strconv.Itoa(itemType))
+	query.Set("title", title)
+	query.Set("smart", "0")
+	query.Set("sectionId", sectionKey)
+	query.Set("uri", plexItemsURI(machineID, ratingKeys))
+	out, err := c.do(ctx, http.MethodPost, "library/collections", query, "plex create collection")
+	if err != nil {
+		return PlexMetadata{}, err
+	}
+	if len(out.MediaContainer.Metadata) == 0 {
+		return PlexMetadata{}, fmt.Errorf("plex create collection: no collection returned")
+	}
+	return out.MediaContainer.Metadata[0], nil
+}
+
+func (c *PlexClient) AddToCollection(ctx context.Context, machineID string, collectionKey string, ratingKeys []string) error {
+	query := url.Values{}
+	query.Set("uri", plexItemsURI(machineID, ratingKeys))
+	_, err := c.do(ctx, http.MethodPut, fmt.Sprintf("library/collections/%s/items", collectionKey), query, "plex add to collection")
+	return err
+}
+
+func (c *PlexClient) RemoveFromCollection(ctx context.Context, collectionKey string, ratingKey string) error {
+	_, err := c.do(ctx, http.MethodDelete, fmt.Sprintf("library/collections/%s/items/%s", collectionKey, ratingKey), nil, "plex remove from collection")
+	return err
+}
+
+func plexItemsURI(machineID string, ratingKeys []string) string {
+	return fmt.Sprintf("server://%s/com.plexapp.plugins.library/library/metadata/%s", machineID, strings.Join(ratingKeys, ","))
+}
+
+func (c *PlexClient) get(ctx context.Context, path string, query url.Values, label string) (plexContainer, error) {
+	return c.do(ctx, http.MethodGet, path, query, label)
+}
+
+func (c *PlexClient) do(ctx context.Context, method string, path string, query url.Values, label string) (plexContainer, error) {
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, c.http.Resolve(target), nil)
+	if err != nil {
+		return plexContainer{}, err
+	}
+	req.Header.Set("X-Plex-Token", c.http.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := doRequest(ctx, c.http.Client, req)
+	if err != nil {
+		return plexContainer{}, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := readBody(resp)
+		return plexContainer{}, fmt.Errorf("%s: status %d: %s", label, resp.StatusCode, string(body))
+	}
+
+	body, err := readBody(resp)
+	if err != nil {
+		return plexContainer{}, err
+	}
+	var out plexContainer
+	// Collection edits may answer with an empty body.
+	if len(bytes.TrimSpace(body)) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return plexContainer{}, fmt.Errorf("decode json: %w", err)
+	}
+	return out, nil
+}
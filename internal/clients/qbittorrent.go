@@ -0,0 +1,134 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type QBittorrentClient struct {
+	http     *HTTPClient
+	username string
+	password string
+	loggedIn bool
+}
+
+type qbittorrentTorrent struct {
+	Hash        string  `json:"hash"`
+	Name        string  `json:"name"`
+	SavePath    string  `json:"save_path"`
+	State       string  `json:"state"`
+	Progress    float64 `json:"progress"`
+	Ratio       float64 `json:"ratio"`
+	SeedingTime int64   `json:"seeding_time"`
+	Size        int64   `json:"size"`
+}
+
+func (c *QBittorrentClient) Name() string {
+	return TorrentClientQBittorrent
+}
+
+// login starts a WebUI session. Without a username the client relies on the
+// WebUI's localhost or subnet authentication bypass.
+func (c *QBittorrentClient) login(ctx context.Context) error {
+	if c.loggedIn || c.username == "" {
+		return nil
+	}
+	form := url.Values{}
+	form.Set("username", c.username)
+	form.Set("password", c.password)
+	req, err := http.NewRequest(http.MethodPost, c.http.Resolve("api/v2/auth/login"), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", c.http.BaseURL.String())
+
+	resp, err := doRequest(ctx, c.http.Client, req)
+	if err != nil {
+		return err
+	}
+	body, _ := readBody(resp)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("qbittorrent login: status %d: %s", resp.StatusCode, string(body))
+	}
+	if strings.TrimSpace(string(body)) != "Ok." {
+		return fmt.Errorf("qbittorrent login: %s", strings.TrimSpace(string(body)))
+	}
+	c.loggedIn = true
+	return nil
+}
+
+func (c *QBittorrentClient) Torrents(ctx context.Context) ([]Torrent, error) {
+	if err := c.login(ctx); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, c.http.Resolve("api/v2/torrents/info"), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doRequest(ctx, c.http.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := readBody(resp)
+		return nil, fmt.Errorf("qbittorrent torrents: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var raw []qbittorrentTorrent
+	if err := decodeJSONBody(resp, &raw); err != nil {
+		return nil, err
+	}
+	out := make([]Torrent, 0, len(raw))
+	for _, t := range raw {
+		out = append(out, Torrent{
+			Hash:           strings.ToLower(t.Hash),
+			Name:           t.Name,
+			SavePath:       t.SavePath,
+			State:          t.State,
+			Seeding:        qbittorrentSeeding(t.State),
+			Complete:       t.Progress >= 1,
+			Ratio:          t.Ratio,
+			SeedingSeconds: t.SeedingTime,
+			SizeBytes:      t.Size,
+		})
+	}
+	return out, nil
+}
+
+func qbittorrentSeeding(state string) bool {
+	switch state {
+	case "uploading", "stalledUP", "forcedUP", "queuedUP", "checkingUP":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *QBittorrentClient) Remove(ctx context.Context, hash string, deleteData bool) error {
+	if err := c.login(ctx); err != nil {
+		return err
+	}
+	form := url.Values{}
+	form.Set("hashes", hash)
+	form.Set("deleteFiles", fmt.Sprintf("%t", deleteData))
+	req, err := http.NewRequest(http.MethodPost, c.http.Resolve("api/v2/torrents/delete"), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := doRequest(ctx, c.http.Client, req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := readBody(resp)
+		return fmt.Errorf("qbittorrent delete %s: status %d: %s", hash, resp.StatusCode, string(body))
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -2,6 +2,7 @@ package clients
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -11,15 +12,24 @@ type RadarrClient struct {
 }
 
 type RadarrMovie struct {
-	ID         int    `json:"id"`
-	Title      string `json:"title"`
-	Year       int    `json:"year"`
-	TMDBID     int    `json:"tmdbId"`
-	IMDBID     string `json:"imdbId"`
-	Path       string `json:"path"`
-	Added      string `json:"added"`
-	SizeOnDisk int64  `json:"sizeOnDisk"`
-	HasFile    bool   `json:"hasFile"`
+	ID         int      `json:"id"`
+	Title      string   `json:"title"`
+	Year       int      `json:"year"`
+	TMDBID     int      `json:"tmdbId"`
+	IMDBID     string   `json:"imdbId"`
+	Path       string   `json:"path"`
+	Added      string   `json:"added"`
+	SizeOnDisk int64    `json:"sizeOnDisk"`
+	HasFile    bool     `json:"hasFile"`
+	Genres     []string `json:"genres"`
+	MovieFile  *struct {
+		Quality struct {
+			Quality struct {
+				Name       string `json:"name"`
+				Resolution int    `json:"resolution"`
+			} `json:"quality"`
+		} `json:"quality"`
+	} `json:"movieFile"`
 }
 
 type RadarrMovieFile struct {
@@ -62,6 +72,34 @@ func (c *RadarrClient) Movies(ctx context.Context) ([]RadarrMovie, error) {
 	return out, nil
 }
 
+func (c *RadarrClient) Movie(ctx context.Context, id int) (*RadarrMovie, error) {
+	url := c.http.Resolve(fmt.Sprintf("api/v3/movie/%d", id))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Api-Key", c.http.APIKey)
+
+	resp, err := doRequest(ctx, c.http.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("radarr movie %d: %w", id, ErrNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := readBody(resp)
+		return nil, fmt.Errorf("radarr movie %d: status %d: %s", id, resp.StatusCode, string(body))
+	}
+
+	var out RadarrMovie
+	if err := decodeJSONBody(resp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (c *RadarrClient) MovieFiles(ctx context.Context, movieID int) ([]RadarrMovieFile, error) {
 	url := c.http.Resolve(fmt.Sprintf("api/v3/moviefile?movieId=%d", movieID))
 	req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -123,3 +161,60 @@ func (c *RadarrClient) DeleteMovie(ctx context.Context, id int, deleteFiles bool
 	}
 	return nil
 }
+
+func (c *RadarrClient) MovieResource(ctx context.Context, id int) (json.RawMessage, error) {
+	return getRaw(ctx, c.http, fmt.Sprintf("api/v3/movie/%d", id), fmt.Sprintf("radarr movie %d", id))
+}
+
+func (c *RadarrClient) AddMovie(ctx context.Context, resource json.RawMessage, path string) error {
+	var movie map[string]any
+	if err := json.Unmarshal(resource, &movie); err != nil {
+		return fmt.Errorf("radarr add movie: decode resource: %w", err)
+	}
+	delete(movie, "id")
+	delete(movie, "movieFile")
+	if path != "" {
+		movie["path"] = path
+	}
+	movie["addOptions"] = map[string]any{"searchForMovie": false}
+	return sendJSON(ctx, c.http, http.MethodPost, "api/v3/movie", movie, "radarr add movie")
+}
+
+// ArrHistoryRecord is a Radarr or Sonarr history event. DownloadID is the
+// download client's ID for the grab, which is the info hash for torrents.
+type ArrHistoryRecord struct {
+	EventType   string `json:"eventType"`
+	DownloadID  string `json:"downloadId"`
+	SourceTitle string `json:"sourceTitle"`
+	Date        string `json:"date"`
+}
+
+func (c *RadarrClient) MovieHistory(ctx context.Context, movieID int) ([]ArrHistoryRecord, error) {
+	raw, err := getRaw(ctx, c.http, fmt.Sprintf("api/v3/history/movie?movieId=%d", movieID), fmt.Sprintf("radarr movie %d history", movieID))
+	if err != nil {
+		return nil, err
+	}
+	var out []ArrHistoryRecord
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("radarr movie %d history: decode: %w", movieID, err)
+	}
+	return out, nil
+}
+
+// ArrRootFolder is a Radarr or Sonarr root folder.
+type ArrRootFolder struct {
+	ID   int    `json:"id"`
+	Path string `json:"path"`
+}
+
+func (c *RadarrClient) RootFolders(ctx context.Context) ([]ArrRootFolder, error) {
+	raw, err := getRaw(ctx, c.http, "api/v3/rootfolder", "radarr root folders")
+	if err != nil {
+		return nil, err
+	}
+	var out []ArrRootFolder
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("radarr root folders: decode: %w", err)
+	}
+	return out, nil
+}
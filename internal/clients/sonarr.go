@@ -2,6 +2,7 @@ package clients
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -11,29 +12,34 @@ type SonarrClient struct {
 }
 
 type SonarrSeries struct {
-	ID         int    `json:"id"`
-	Title      string `json:"title"`
-	Year       int    `json:"year"`
-	TVDBID     int    `json:"tvdbId"`
-	IMDBID     string `json:"imdbId"`
-	Status     string `json:"status"`
-	Path       string `json:"path"`
-	Added      string `json:"added"`
+	ID         int      `json:"id"`
+	Title      string   `json:"title"`
+	Year       int      `json:"year"`
+	TVDBID     int      `json:"tvdbId"`
+	IMDBID     string   `json:"imdbId"`
+	Status     string   `json:"status"`
+	Path       string   `json:"path"`
+	Added      string   `json:"added"`
+	SeriesType string   `json:"seriesType"`
+	Network    string   `json:"network"`
+	Genres     []string `json:"genres"`
 	Statistics struct {
 		SizeOnDisk int64 `json:"sizeOnDisk"`
 	} `json:"statistics"`
 }
 
 type SonarrSeriesDetail struct {
-	ID      int            `json:"id"`
-	Title   string         `json:"title"`
+	SonarrSeries
 	Seasons []SonarrSeason `json:"seasons"`
 }
 
 type SonarrSeason struct {
-	SeasonNumber int `json:"seasonNumber"`
+	SeasonNumber int  `json:"seasonNumber"`
+	Monitored    bool `json:"monitored"`
 	Statistics   struct {
-		EpisodeFileCount int `json:"episodeFileCount"`
+		EpisodeFileCount int   `json:"episodeFileCount"`
+		EpisodeCount     int   `json:"episodeCount"`
+		SizeOnDisk       int64 `json:"sizeOnDisk"`
 	} `json:"statistics"`
 }
 
@@ -45,9 +51,12 @@ type SonarrEpisode struct {
 }
 
 type SonarrEpisodeFile struct {
-	ID           int `json:"id"`
-	SeriesID     int `json:"seriesId"`
-	SeasonNumber int `json:"seasonNumber"`
+	ID           int    `json:"id"`
+	SeriesID     int    `json:"seriesId"`
+	SeasonNumber int    `json:"seasonNumber"`
+	Path         string `json:"path"`
+	Size         int64  `json:"size"`
+	DateAdded    string `json:"dateAdded"`
 }
 
 func NewSonarrClient(baseURL, apiKey string) (*SonarrClient, error) {
@@ -94,6 +103,10 @@ func (c *SonarrClient) SeriesByID(ctx context.Context, id int) (*SonarrSeriesDet
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("sonarr series %d: %w", id, ErrNotFound)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := readBody(resp)
 		return nil, fmt.Errorf("sonarr series %d: status %d: %s", id, resp.StatusCode, string(body))
@@ -213,3 +226,81 @@ func (c *SonarrClient) DeleteSeries(ctx context.Context, id int, deleteFiles boo
 	}
 	return nil
 }
+
+func (c *SonarrClient) SeriesResource(ctx context.Context, id int) (json.RawMessage, error) {
+	return getRaw(ctx, c.http, fmt.Sprintf("api/v3/series/%d", id), fmt.Sprintf("sonarr series %d", id))
+}
+
+func (c *SonarrClient) AddSeries(ctx context.Context, resource json.RawMessage, path string) error {
+	var series map[string]any
+	if err := json.Unmarshal(resource, &series); err != nil {
+		return fmt.Errorf("sonarr add series: decode resource: %w", err)
+	}
+	delete(series, "id")
+	delete(series, "statistics")
+	if path != "" {
+		series["path"] = path
+	}
+	series["addOptions"] = map[string]any{"searchForMissingEpisodes": false}
+	return sendJSON(ctx, c.http, http.MethodPost, "api/v3/series", series, "sonarr add series")
+}
+
+// SetSeasonMonitored updates a single season's monitored flag by sending the
+// full series resource back with the season changed.
+func (c *SonarrClient) SetSeasonMonitored(ctx context.Context, seriesID int, seasonNumber int, monitored bool) error {
+	resource, err := c.SeriesResource(ctx, seriesID)
+	if err != nil {
+		return err
+	}
+	var series map[string]any
+	if err := json.Unmarshal(resource, &series); err != nil {
+		return fmt.Errorf("sonarr series %d: decode resource: %w", seriesID, err)
+	}
+	seasons, _ := series["seasons"].([]any)
+	found := false
+	for _, raw := range seasons {
+		season, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if number, ok := season["seasonNumber"].(float64); ok && int(number) == seasonNumber {
+			season["monitored"] = monitored
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("sonarr series %d: season %d not found", seriesID, seasonNumber)
+	}
+	label := fmt.Sprintf("sonarr update series %d", seriesID)
+	return sendJSON(ctx, c.http, http.MethodPut, fmt.Sprintf("api/v3/series/%d", seriesID), series, label)
+}
+
+// SeriesHistory returns the history of a series, limited to one season when
+// seasonNumber is not nil.
+func (c *SonarrClient) SeriesHistory(ctx context.Context, seriesID int, seasonNumber *int) ([]ArrHistoryRecord, error) {
+	path := fmt.Sprintf("api/v3/history/series?seriesId=%d", seriesID)
+	if seasonNumber != nil {
+		path += fmt.Sprintf("&seasonNumber=%d", *seasonNumber)
+	}
+	raw, err := getRaw(ctx, c.http, path, fmt.Sprintf("sonarr series %d history", seriesID))
+	if err != nil {
+		return nil, err
+	}
+	var out []ArrHistoryRecord
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("sonarr series %d history: decode: %w", seriesID, err)
+	}
+	return out, nil
+}
+
+func (c *SonarrClient) RootFolders(ctx context.Context) ([]ArrRootFolder, error) {
+	raw, err := getRaw(ctx, c.http, "api/v3/rootfolder", "sonarr root folders")
+	if err != nil {
+		return nil, err
+	}
+	var out []ArrRootFolder
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("sonarr root folders: decode: %w", err)
+	}
+	return out, nil
+}
@@ -104,6 +104,55 @@ func (c *TautulliClient) historyPage(ctx context.Context, start, length int) ([]
 	return items, total, nil
 }
 
+// Activity returns the sessions currently streaming according to Tautulli.
+func (c *TautulliClient) Activity(ctx context.Context) ([]map[string]any, error) {
+	u, err := url.Parse(c.http.Resolve("api/v2"))
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("cmd", "get_activity")
+	q.Set("apikey", c.http.APIKey)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doRequest(ctx, c.http.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := readBody(resp)
+		return nil, fmt.Errorf("tautulli activity: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var payload map[string]any
+	if err := decodeJSONBody(resp, &payload); err != nil {
+		return nil, err
+	}
+	respMap, ok := payload["response"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("tautulli activity: missing response")
+	}
+	if result, ok := respMap["result"].(string); ok && result != "success" {
+		return nil, fmt.Errorf("tautulli activity: result %s", result)
+	}
+	dataMap, ok := respMap["data"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("tautulli activity: missing data")
+	}
+	sessionsRaw, _ := dataMap["sessions"].([]any)
+	sessions := make([]map[string]any, 0, len(sessionsRaw))
+	for _, entry := range sessionsRaw {
+		if session, ok := entry.(map[string]any); ok {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
 func getIntFromMap(m map[string]any, key string) int {
 	val, ok := m[key]
 	if !ok {
@@ -124,6 +173,10 @@ func coerceInt(val any) int {
 		if i, err := strconv.Atoi(v.String()); err == nil {
 			return i
 		}
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
 	}
 	return 0
 }
@@ -0,0 +1,80 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http/cookiejar"
+	"strings"
+)
+
+const (
+	TorrentClientQBittorrent  = "qbittorrent"
+	TorrentClientTransmission = "transmission"
+	TorrentClientDeluge       = "deluge"
+)
+
+// Torrent is the client-neutral view of a torrent. Hash is the lowercase
+// info hash, which is what Radarr and Sonarr record as downloadId.
+type Torrent struct {
+	Hash           string
+	Name           string
+	SavePath       string
+	State          string
+	Seeding        bool
+	Complete       bool
+	Ratio          float64
+	SeedingSeconds int64
+	SizeBytes      int64
+}
+
+// TorrentClient is implemented by every supported download client.
+type TorrentClient interface {
+	Name() string
+	Torrents(ctx context.Context) ([]Torrent, error)
+	Remove(ctx context.Context, hash string, deleteData bool) error
+}
+
+// NewTorrentClient builds the client for kind (qbittorrent, transmission or
+// deluge). Deluge only uses the password.
+func NewTorrentClient(kind, baseURL, username, password string) (TorrentClient, error) {
+	hc, err := NewHTTPClient(baseURL, "")
+	if err != nil {
+		return nil, err
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	hc.Client.Jar = jar
+	switch strings.ToLower(kind) {
+	case TorrentClientQBittorrent:
+		return &QBittorrentClient{http: hc, username: username, password: password}, nil
+	case TorrentClientTransmission:
+		return &TransmissionClient{http: hc, username: username, password: password}, nil
+	case TorrentClientDeluge:
+		return &DelugeClient{http: hc, password: password}, nil
+	default:
+		return nil, fmt.Errorf("unsupported torrent client %q", kind)
+	}
+}
+
+// TorrentHashes returns the unique, lowercased download IDs of grab and
+// import events, which for torrent clients are the info hashes.
+func TorrentHashes(records []ArrHistoryRecord) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, record := range records {
+		switch record.EventType {
+		case "grabbed", "downloadFolderImported":
+		default:
+			continue
+		}
+		hash := strings.ToLower(strings.TrimSpace(record.DownloadID))
+		if hash == "" || seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		out = append(out, hash)
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const transmissionSessionHeader = "X-Transmission-Session-Id"
+
+type TransmissionClient struct {
+	http      *HTTPClient
+	username  string
+	password  string
+	sessionID string
+}
+
+// Transmission torrent status values, see the RPC spec.
+const (
+	transmissionStatusSeedWait = 5
+	transmissionStatusSeed     = 6
+)
+
+type transmissionTorrent struct {
+	HashString     string  `json:"hashString"`
+	Name           string  `json:"name"`
+	DownloadDir    string  `json:"downloadDir"`
+	Status         int     `json:"status"`
+	PercentDone    float64 `json:"percentDone"`
+	UploadRatio    float64 `json:"uploadRatio"`
+	SecondsSeeding int64   `json:"secondsSeeding"`
+	TotalSize      int64   `json:"totalSize"`
+}
+
+func (c *TransmissionClient) Name() string {
+	return TorrentClientTransmission
+}
+
+// call performs an RPC request, retrying once when Transmission hands out a
+// new session ID with a 409.
+func (c *TransmissionClient) call(ctx context.Context, method string, arguments any, out any) error {
+	payload, err := json.Marshal(map[string]any{"method": method, "arguments": arguments})
+	if err != nil {
+		return fmt.Errorf("transmission %s: encode: %w", method, err)
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, c.http.Resolve("transmission/rpc"), bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.sessionID != "" {
+			req.Header.Set(transmissionSessionHeader, c.sessionID)
+		}
+		if c.username != "" {
+			req.SetBasicAuth(c.username, c.password)
+		}
+
+		resp, err := doRequest(ctx, c.http.Client, req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusConflict {
+			resp.Body.Close()
+			c.sessionID = resp.Header.Get(transmissionSessionHeader)
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := readBody(resp)
+			return fmt.Errorf("transmission %s: status %d: %s", method, resp.StatusCode, string(body))
+		}
+
+		var envelope struct {
+			Result    string          `json:"result"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := decodeJSONBody(resp, &envelope); err != nil {
+			return err
+		}
+		if envelope.Result != "success" {
+			return fmt.Errorf("transmission %s: %s", method, envelope.Result)
+		}
+		if out == nil {
+			return nil
+		}
+		if err := json.Unmarshal(envelope.Arguments, out); err != nil {
+			return fmt.Errorf("transmission %s: decode: %w", method, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("transmission %s: session id rejected", method)
+}
+
+func (c *TransmissionClient) Torrents(ctx context.Context) ([]Torrent, error) {
+	args := map[string]any{
+		"fields": []string{"hashString", "name", "downloadDir", "status", "percentDone", "uploadRatio", "secondsSeeding", "totalSize"},
+	}
+	var result struct {
+		Torrents []transmissionTorrent `json:"torrents"`
+	}
+	if err := c.call(ctx, "torrent-get", args, &result); err != nil {
+		return nil, err
+	}
+	out := make([]Torrent, 0, len(result.Torrents))
+	for _, t := range result.Torrents {
+		ratio := t.UploadRatio
+		if ratio < 0 {
+			ratio = 0
+		}
+		out = append(out, Torrent{
+			Hash:           strings.ToLower(t.HashString),
+			Name:           t.Name,
+			SavePath:       t.DownloadDir,
+			State:          transmissionState(t.Status),
+			Seeding:        t.Status == transmissionStatusSeed || t.Status == transmissionStatusSeedWait,
+			Complete:       t.PercentDone >= 1,
+			Ratio:          ratio,
+			SeedingSeconds: t.SecondsSeeding,
+			SizeBytes:      t.TotalSize,
+		})
+	}
+	return out, nil
+}
+
+func transmissionState(status int) string {
+	switch status {
+	case 0:
+		return "stopped"
+	case 1:
+		return "check_wait"
+	case 2:
+		return "checking"
+	case 3:
+		return "download_wait"
+	case 4:
+		return "downloading"
+	case transmissionStatusSeedWait:
+		return "seed_wait"
+	case transmissionStatusSeed:
+		return "seeding"
+	default:
+		return fmt.Sprintf("status_%d", status)
+	}
+}
+
+func (c *TransmissionClient) Remove(ctx context.Context, hash string, deleteData bool) error {
+	args := map[string]any{
+		"ids":               []string{hash},
+		"delete-local-data": deleteData,
+	}
+	return c.call(ctx, "torrent-remove", args, nil)
+}
@@ -3,53 +3,96 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go-unraid-clean/internal/apply"
 	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/notify"
 	"go-unraid-clean/internal/report"
 
 	"github.com/spf13/cobra"
 )
 
 var applyIn string
+var applyFormat string
+var applyBase string
 var applyConfirm bool
+var applyResume bool
+var applySkipRevalidate bool
+var applyMaxReportAge time.Duration
+var applyIgnoreLeavingSoon bool
 
 var applyCmd = &cobra.Command{
 	Use:   "apply",
 	Short: "Apply a previously generated cleanup report",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+		ctx, stop := signalContext()
+		defer stop()
 
 		cfg, err := config.Load(configPath)
 		if err != nil {
 			return err
 		}
 
-		rep, err := report.ReadJSON(applyIn)
+		rep, err := loadReport(applyIn, applyFormat, applyBase)
 		if err != nil {
 			return err
 		}
 
 		summary := report.Summarize(rep)
 		fmt.Printf("Items: %d\n", summary.Total)
+		fmt.Printf("Size: %s GiB on disk, %s GiB reclaimable\n", formatGiB(summary.TotalBytes), formatGiB(summary.ReclaimableBytes))
 		if len(summary.ByType) > 0 {
 			fmt.Printf("By type: %v\n", summary.ByType)
 		}
 		if len(summary.ByReason) > 0 {
 			fmt.Printf("By reason: %v\n", summary.ByReason)
 		}
+		if len(summary.ByAction) > 0 {
+			fmt.Printf("By action: %v\n", summary.ByAction)
+		}
+		printDiskSummary(summary)
+
+		if err := apply.CheckReportAge(rep, applyMaxReportAge, time.Now().UTC()); err != nil {
+			return err
+		}
 
 		if !applyConfirm {
-			fmt.Println("Review complete. Re-run with --confirm to apply deletions.")
+			fmt.Println("Review complete. Re-run with --confirm to apply actions.")
 			return nil
 		}
 
-		return apply.Run(ctx, cfg, rep)
+		outcome, err := apply.Run(ctx, configPath, cfg, rep, apply.Options{
+			ReportPath:        applyIn,
+			ProgressPath:      apply.ProgressPath(applyIn),
+			Resume:            applyResume,
+			Revalidate:        !applySkipRevalidate,
+			MaxReportAge:      applyMaxReportAge,
+			IgnoreLeavingSoon: applyIgnoreLeavingSoon,
+		})
+		fmt.Printf("Done: %d, failed: %d, skipped: %d, kept: %d", outcome.Done, outcome.Failed, outcome.Skipped, outcome.Kept)
+		if outcome.AlreadyDone > 0 {
+			fmt.Printf(", already done: %d", outcome.AlreadyDone)
+		}
+		if outcome.Remaining > 0 {
+			fmt.Printf(", not attempted: %d", outcome.Remaining)
+		}
+		fmt.Println()
+
+		results := notify.Results{Done: outcome.Done, Failed: outcome.Failed, Skipped: outcome.Skipped, Kept: outcome.Kept}
+		notify.Send(context.WithoutCancel(ctx), cfg.Notify, notify.ApplySummary(config.NotifyEventApply, outcome.Applied, applyIn, cfg.Notify.Top, results, err))
+		return err
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(applyCmd)
-	applyCmd.Flags().StringVar(&applyIn, "in", "review.json", "Input review report to apply")
-	applyCmd.Flags().BoolVar(&applyConfirm, "confirm", false, "Actually delete items from Sonarr/Radarr")
+	applyCmd.Flags().StringVar(&applyIn, "in", "review.json", "Input review report to apply (JSON or CSV)")
+	applyCmd.Flags().StringVar(&applyFormat, "format", report.FormatAuto, "Input format: auto, json, or csv")
+	applyCmd.Flags().StringVar(&applyBase, "base", "", "Original JSON report to map CSV rows onto by radarr_id/sonarr_id")
+	applyCmd.Flags().BoolVar(&applySkipRevalidate, "skip-revalidate", false, "Do not re-check items against live Sonarr/Radarr/Tautulli state before deleting")
+	applyCmd.Flags().DurationVar(&applyMaxReportAge, "max-report-age", 0, "Refuse to apply reports older than this (e.g. 72h); 0 disables")
+	applyCmd.Flags().BoolVar(&applyIgnoreLeavingSoon, "ignore-leaving-soon", false, "Apply destructive actions without waiting out the Leaving Soon grace period")
+	applyCmd.Flags().BoolVar(&applyResume, "resume", false, "Continue an interrupted apply, skipping items already done")
+	applyCmd.Flags().BoolVar(&applyConfirm, "confirm", false, "Actually apply item actions against Sonarr/Radarr")
 }
@@ -3,8 +3,10 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
+	"go-unraid-clean/internal/arr"
 	"go-unraid-clean/internal/clients"
 	"go-unraid-clean/internal/config"
 
@@ -24,28 +26,25 @@ var enrichCmd = &cobra.Command{
 			return err
 		}
 
-		radarr, err := clients.NewRadarrClient(cfg.Radarr.BaseURL, cfg.Radarr.APIKey)
-		if err != nil {
-			return err
-		}
-		sonarr, err := clients.NewSonarrClient(cfg.Sonarr.BaseURL, cfg.Sonarr.APIKey)
+		arrs, err := arr.New(cfg)
 		if err != nil {
 			return err
 		}
 
-		movies, err := radarr.Movies(ctx)
-		if err != nil {
-			return err
+		movies := map[string][]clients.RadarrMovie{}
+		for _, radarr := range arrs.Radarr {
+			if movies[radarr.Name], err = radarr.Movies(ctx); err != nil {
+				return err
+			}
 		}
-		series, err := sonarr.Series(ctx)
-		if err != nil {
-			return err
+		series := map[string][]clients.SonarrSeries{}
+		for _, sonarr := range arrs.Sonarr {
+			if series[sonarr.Name], err = sonarr.Series(ctx); err != nil {
+				return err
+			}
 		}
 
-		movieIndex := buildMovieIndex(movies)
-		seriesIndex := buildSeriesIndex(series)
-
-		changes := enrichExceptions(&cfg, movieIndex, seriesIndex)
+		changes := enrichExceptions(&cfg, movies, series)
 		if len(changes) == 0 {
 			fmt.Println("No exception entries to enrich.")
 			return nil
@@ -121,73 +120,114 @@ func buildSeriesIndex(series []clients.SonarrSeries) seriesIndex {
 	return idx
 }
 
-func enrichExceptions(cfg *config.Config, movies movieIndex, series seriesIndex) []string {
-	changes := []string{}
+// enrichExceptions backfills the global exceptions from every instance (arr
+// IDs only from the first, which they refer to) and each instance's scoped
+// exceptions from that instance alone.
+func enrichExceptions(cfg *config.Config, movies map[string][]clients.RadarrMovie, series map[string][]clients.SonarrSeries) []string {
+	var allMovies []clients.RadarrMovie
+	for _, inst := range cfg.Radarr {
+		allMovies = append(allMovies, movies[inst.Name]...)
+	}
+	var allSeries []clients.SonarrSeries
+	for _, inst := range cfg.Sonarr {
+		allSeries = append(allSeries, series[inst.Name]...)
+	}
 
-	for _, id := range cfg.Exceptions.Movies.RadarrIDs {
-		if movie, ok := movies.byRadarr[id]; ok {
-			if change := applyMovieDetails(cfg, movie); change != "" {
-				changes = append(changes, change)
-			}
+	changes := enrichMovies(&cfg.Exceptions.Movies, buildMovieIndex(movies[cfg.Radarr.First()]), buildMovieIndex(allMovies), "")
+	changes = append(changes, enrichSeries(&cfg.Exceptions.Series, buildSeriesIndex(series[cfg.Sonarr.First()]), buildSeriesIndex(allSeries), "")...)
+
+	names := make([]string, 0, len(cfg.Exceptions.Instances))
+	for name := range cfg.Exceptions.Instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		scoped := cfg.Exceptions.Instances[name]
+		movieIdx := buildMovieIndex(movies[name])
+		seriesIdx := buildSeriesIndex(series[name])
+		changes = append(changes, enrichMovies(&scoped.Movies, movieIdx, movieIdx, name)...)
+		changes = append(changes, enrichSeries(&scoped.Series, seriesIdx, seriesIdx, name)...)
+		cfg.Exceptions.Instances[name] = scoped
+	}
+	return changes
+}
+
+// enrichMovies looks up radarr_ids in byArrID and TMDB/IMDB IDs in byExternal.
+func enrichMovies(exc *config.MovieExceptions, byArrID movieIndex, byExternal movieIndex, scope string) []string {
+	changes := []string{}
+	add := func(movie clients.RadarrMovie) {
+		if change := applyMovieDetails(exc, movie); change != "" {
+			changes = append(changes, scopedChange(scope, change))
 		}
 	}
-	for _, id := range cfg.Exceptions.Movies.TMDBIDs {
-		if movie, ok := movies.byTMDB[id]; ok {
-			if change := applyMovieDetails(cfg, movie); change != "" {
-				changes = append(changes, change)
-			}
+	for _, id := range exc.RadarrIDs {
+		if movie, ok := byArrID.byRadarr[id]; ok {
+			add(movie)
 		}
 	}
-	for _, id := range cfg.Exceptions.Movies.IMDBIDs {
-		if movie, ok := movies.byIMDB[strings.ToLower(id)]; ok {
-			if change := applyMovieDetails(cfg, movie); change != "" {
-				changes = append(changes, change)
-			}
+	for _, id := range exc.TMDBIDs {
+		if movie, ok := byExternal.byTMDB[id]; ok {
+			add(movie)
 		}
 	}
+	for _, id := range exc.IMDBIDs {
+		if movie, ok := byExternal.byIMDB[strings.ToLower(id)]; ok {
+			add(movie)
+		}
+	}
+	return changes
+}
 
-	for _, id := range cfg.Exceptions.Series.SonarrIDs {
-		if show, ok := series.bySonarr[id]; ok {
-			if change := applySeriesDetails(cfg, show); change != "" {
-				changes = append(changes, change)
-			}
+// enrichSeries looks up sonarr_ids in byArrID and TVDB/IMDB IDs in byExternal.
+func enrichSeries(exc *config.SeriesExceptions, byArrID seriesIndex, byExternal seriesIndex, scope string) []string {
+	changes := []string{}
+	add := func(show clients.SonarrSeries) {
+		if change := applySeriesDetails(exc, show); change != "" {
+			changes = append(changes, scopedChange(scope, change))
 		}
 	}
-	for _, id := range cfg.Exceptions.Series.TVDBIDs {
-		if show, ok := series.byTVDB[id]; ok {
-			if change := applySeriesDetails(cfg, show); change != "" {
-				changes = append(changes, change)
-			}
+	for _, id := range exc.SonarrIDs {
+		if show, ok := byArrID.bySonarr[id]; ok {
+			add(show)
 		}
 	}
-	for _, id := range cfg.Exceptions.Series.IMDBIDs {
-		if show, ok := series.byIMDB[strings.ToLower(id)]; ok {
-			if change := applySeriesDetails(cfg, show); change != "" {
-				changes = append(changes, change)
-			}
+	for _, id := range exc.TVDBIDs {
+		if show, ok := byExternal.byTVDB[id]; ok {
+			add(show)
+		}
+	}
+	for _, id := range exc.IMDBIDs {
+		if show, ok := byExternal.byIMDB[strings.ToLower(id)]; ok {
+			add(show)
 		}
 	}
-
 	return changes
 }
 
-func applyMovieDetails(cfg *config.Config, movie clients.RadarrMovie) string {
+func scopedChange(scope string, change string) string {
+	if scope == "" {
+		return change
+	}
+	return fmt.Sprintf("%s %s", scope, change)
+}
+
+func applyMovieDetails(exc *config.MovieExceptions, movie clients.RadarrMovie) string {
 	changed := false
-	before := len(cfg.Exceptions.Movies.Titles)
-	cfg.Exceptions.Movies.Titles = config.AddUniqueString(cfg.Exceptions.Movies.Titles, movie.Title)
-	changed = changed || len(cfg.Exceptions.Movies.Titles) > before
+	before := len(exc.Titles)
+	exc.Titles = config.AddUniqueString(exc.Titles, movie.Title)
+	changed = changed || len(exc.Titles) > before
 
-	before = len(cfg.Exceptions.Movies.PathPrefixes)
-	cfg.Exceptions.Movies.PathPrefixes = config.AddUniqueString(cfg.Exceptions.Movies.PathPrefixes, movie.Path)
-	changed = changed || len(cfg.Exceptions.Movies.PathPrefixes) > before
+	before = len(exc.PathPrefixes)
+	exc.PathPrefixes = config.AddUniqueString(exc.PathPrefixes, movie.Path)
+	changed = changed || len(exc.PathPrefixes) > before
 
-	before = len(cfg.Exceptions.Movies.IMDBIDs)
-	cfg.Exceptions.Movies.IMDBIDs = config.AddUniqueString(cfg.Exceptions.Movies.IMDBIDs, movie.IMDBID)
-	changed = changed || len(cfg.Exceptions.Movies.IMDBIDs) > before
+	before = len(exc.IMDBIDs)
+	exc.IMDBIDs = config.AddUniqueString(exc.IMDBIDs, movie.IMDBID)
+	changed = changed || len(exc.IMDBIDs) > before
 
-	before = len(cfg.Exceptions.Movies.TMDBIDs)
-	cfg.Exceptions.Movies.TMDBIDs = config.AddUniqueInt(cfg.Exceptions.Movies.TMDBIDs, movie.TMDBID)
-	changed = changed || len(cfg.Exceptions.Movies.TMDBIDs) > before
+	before = len(exc.TMDBIDs)
+	exc.TMDBIDs = config.AddUniqueInt(exc.TMDBIDs, movie.TMDBID)
+	changed = changed || len(exc.TMDBIDs) > before
 
 	if !changed {
 		return ""
@@ -195,23 +235,23 @@ func applyMovieDetails(cfg *config.Config, movie clients.RadarrMovie) string {
 	return fmt.Sprintf("movie: %s", movie.Title)
 }
 
-func applySeriesDetails(cfg *config.Config, show clients.SonarrSeries) string {
+func applySeriesDetails(exc *config.SeriesExceptions, show clients.SonarrSeries) string {
 	changed := false
-	before := len(cfg.Exceptions.Series.Titles)
-	cfg.Exceptions.Series.Titles = config.AddUniqueString(cfg.Exceptions.Series.Titles, show.Title)
-	changed = changed || len(cfg.Exceptions.Series.Titles) > before
+	before := len(exc.Titles)
+	exc.Titles = config.AddUniqueString(exc.Titles, show.Title)
+	changed = changed || len(exc.Titles) > before
 
-	before = len(cfg.Exceptions.Series.PathPrefixes)
-	cfg.Exceptions.Series.PathPrefixes = config.AddUniqueString(cfg.Exceptions.Series.PathPrefixes, show.Path)
-	changed = changed || len(cfg.Exceptions.Series.PathPrefixes) > before
+	before = len(exc.PathPrefixes)
+	exc.PathPrefixes = config.AddUniqueString(exc.PathPrefixes, show.Path)
+	changed = changed || len(exc.PathPrefixes) > before
 
-	before = len(cfg.Exceptions.Series.IMDBIDs)
-	cfg.Exceptions.Series.IMDBIDs = config.AddUniqueString(cfg.Exceptions.Series.IMDBIDs, show.IMDBID)
-	changed = changed || len(cfg.Exceptions.Series.IMDBIDs) > before
+	before = len(exc.IMDBIDs)
+	exc.IMDBIDs = config.AddUniqueString(exc.IMDBIDs, show.IMDBID)
+	changed = changed || len(exc.IMDBIDs) > before
 
-	before = len(cfg.Exceptions.Series.TVDBIDs)
-	cfg.Exceptions.Series.TVDBIDs = config.AddUniqueInt(cfg.Exceptions.Series.TVDBIDs, show.TVDBID)
-	changed = changed || len(cfg.Exceptions.Series.TVDBIDs) > before
+	before = len(exc.TVDBIDs)
+	exc.TVDBIDs = config.AddUniqueInt(exc.TVDBIDs, show.TVDBID)
+	changed = changed || len(exc.TVDBIDs) > before
 
 	if !changed {
 		return ""
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/journal"
+	"go-unraid-clean/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var historyJournal string
+var historyTitle string
+var historyAction string
+var historyType string
+var historySince string
+var historyUntil string
+var historyFailed bool
+var historyMonthly bool
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List past destructive actions from the audit journal",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := historyJournal
+		if path == "" {
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			path = state.FromConfig(cfg).JournalPath()
+		}
+
+		filter := journal.Filter{
+			Title:  historyTitle,
+			Action: historyAction,
+			Type:   historyType,
+			Failed: historyFailed,
+		}
+		var err error
+		if filter.Since, err = parseHistoryDate(historySince); err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+		if filter.Until, err = parseHistoryDate(historyUntil); err != nil {
+			return fmt.Errorf("--until: %w", err)
+		}
+
+		entries, err := journal.Read(path)
+		if err != nil {
+			return err
+		}
+		entries = journal.Select(entries, filter)
+		if len(entries) == 0 {
+			fmt.Println("No matching journal entries.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		if !historyMonthly {
+			fmt.Fprintln(w, "TIME\tCOMMAND\tACTOR\tACTION\tTYPE\tTITLE\tFREED(GiB)\tSTATUS\tREPORT")
+			for _, entry := range entries {
+				status := "ok"
+				if !entry.Success {
+					status = "failed: " + entry.Error
+				}
+				fmt.Fprintf(
+					w,
+					"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					entry.Timestamp.UTC().Format(time.RFC3339),
+					entry.Command,
+					entry.Actor,
+					entry.Action,
+					entry.Type,
+					entry.Title,
+					formatGiB(entry.BytesFreed),
+					status,
+					entry.Report,
+				)
+			}
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintln(w, "MONTH\tACTIONS\tFREED(GiB)")
+		var totalBytes int64
+		var totalActions int
+		for _, month := range journal.MonthlyTotals(entries) {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", month.Month, month.Actions, formatGiB(month.BytesFreed))
+			totalBytes += month.BytesFreed
+			totalActions += month.Actions
+		}
+		fmt.Fprintf(w, "total\t%d\t%s\n", totalActions, formatGiB(totalBytes))
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().StringVar(&historyJournal, "journal", "", "Journal path (defaults to journal.path from config)")
+	historyCmd.Flags().StringVar(&historyTitle, "title", "", "Only entries whose title contains this text")
+	historyCmd.Flags().StringVar(&historyAction, "action", "", "Only entries with this action")
+	historyCmd.Flags().StringVar(&historyType, "type", "", "Only entries with this item type (movie, series)")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only entries on or after this date (YYYY-MM-DD or YYYY-MM)")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "Only entries before this date (YYYY-MM-DD or YYYY-MM)")
+	historyCmd.Flags().BoolVar(&historyFailed, "failed", false, "Only failed actions")
+	historyCmd.Flags().BoolVar(&historyMonthly, "monthly", false, "Only print totals freed per month")
+}
+
+func parseHistoryDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+func formatGiB(bytes int64) string {
+	if bytes <= 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%.2f", float64(bytes)/(1024*1024*1024))
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"fmt"
+
+	"go-unraid-clean/internal/report"
+)
+
+func loadReport(path string, format string, basePath string) (*report.Report, error) {
+	rep, err := report.Read(path, format)
+	if err != nil {
+		return nil, err
+	}
+	if basePath == "" {
+		return rep, nil
+	}
+	base, err := report.ReadJSON(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("base report: %w", err)
+	}
+	return report.Reconcile(base, rep), nil
+}
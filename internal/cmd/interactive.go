@@ -5,12 +5,15 @@ import (
 
 	"go-unraid-clean/internal/config"
 	"go-unraid-clean/internal/interactive"
+	"go-unraid-clean/internal/notify"
 	"go-unraid-clean/internal/report"
 
 	"github.com/spf13/cobra"
 )
 
 var interactiveIn string
+var interactiveFormat string
+var interactiveBase string
 
 var interactiveCmd = &cobra.Command{
 	Use:   "interactive",
@@ -23,16 +26,23 @@ var interactiveCmd = &cobra.Command{
 			return err
 		}
 
-		rep, err := report.ReadJSON(interactiveIn)
+		rep, err := loadReport(interactiveIn, interactiveFormat, interactiveBase)
 		if err != nil {
 			return err
 		}
 
-		return interactive.Run(ctx, configPath, cfg, rep)
+		outcome, err := interactive.Run(ctx, configPath, cfg, rep, interactiveIn)
+		results := notify.Results{Done: len(outcome.Applied), Failed: len(outcome.Failures), Kept: outcome.Kept}
+		summary := notify.ApplySummary(config.NotifyEventInteractive, outcome.Applied, interactiveIn, cfg.Notify.Top, results, err)
+		summary.Failures = append(outcome.Failures, summary.Failures...)
+		notify.Send(context.WithoutCancel(ctx), cfg.Notify, summary)
+		return err
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(interactiveCmd)
-	interactiveCmd.Flags().StringVar(&interactiveIn, "in", "review.json", "Input review report to apply")
+	interactiveCmd.Flags().StringVar(&interactiveIn, "in", "review.json", "Input review report to apply (JSON or CSV)")
+	interactiveCmd.Flags().StringVar(&interactiveFormat, "format", report.FormatAuto, "Input format: auto, json, or csv")
+	interactiveCmd.Flags().StringVar(&interactiveBase, "base", "", "Original JSON report to map CSV rows onto by radarr_id/sonarr_id")
 }
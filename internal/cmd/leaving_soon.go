@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/leavingsoon"
+	"go-unraid-clean/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var leavingSoonCmd = &cobra.Command{
+	Use:   "leaving-soon",
+	Short: "List items in the Leaving Soon collection and when they may be deleted",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if !cfg.LeavingSoon.Enabled {
+			fmt.Println("leaving_soon is not enabled.")
+		}
+		st, err := leavingsoon.Load(state.FromConfig(cfg))
+		if err != nil {
+			return err
+		}
+		printLeavingSoon(st, cfg.LeavingSoon)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(leavingSoonCmd)
+}
+
+func printLeavingSoon(st *leavingsoon.State, cfg config.LeavingSoon) {
+	if len(st.Items) == 0 {
+		fmt.Println("Nothing is leaving soon.")
+		return
+	}
+	entries := make([]leavingsoon.Entry, 0, len(st.Items))
+	for _, entry := range st.Items {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].AddedAt.Before(entries[j].AddedAt)
+	})
+	w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tTITLE\tFIRST_FLAGGED\tADDED\tDELETE_AFTER\tIN_PLEX")
+	for _, entry := range entries {
+		inPlex := "yes"
+		if entry.PlexKey == "" {
+			inPlex = "no"
+		}
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Type,
+			entry.Title,
+			entry.FirstFlagged.Format(time.RFC3339),
+			entry.AddedAt.Format(time.RFC3339),
+			entry.DeleteAfter(cfg).Format("2006-01-02"),
+			inPlex,
+		)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/notify"
+
+	"github.com/spf13/cobra"
+)
+
+var notifyTestSink string
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage scan and apply notifications",
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a sample summary to every configured notification sink",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signalContext()
+		defer cancel()
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if len(cfg.Notify.Sinks) == 0 {
+			return fmt.Errorf("no notify.sinks configured")
+		}
+
+		summary := sampleSummary()
+		failed := 0
+		matched := false
+		for _, sinkCfg := range cfg.Notify.Sinks {
+			if notifyTestSink != "" && sinkCfg.Name != notifyTestSink {
+				continue
+			}
+			matched = true
+			sink, err := notify.New(sinkCfg)
+			if err == nil {
+				err = sink.Send(ctx, summary)
+			}
+			if err != nil {
+				failed++
+				fmt.Printf("%s (%s): failed: %s\n", sinkCfg.Name, sinkCfg.Type, err)
+				continue
+			}
+			fmt.Printf("%s (%s): sent\n", sinkCfg.Name, sinkCfg.Type)
+		}
+		if !matched {
+			return fmt.Errorf("no notify sink named %q", notifyTestSink)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d notification sinks failed", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+	notifyTestCmd.Flags().StringVar(&notifyTestSink, "sink", "", "Only send to the sink with this name")
+}
+
+func sampleSummary() notify.Summary {
+	const gib = 1024 * 1024 * 1024
+	return notify.Summary{
+		Event:      notify.EventTest,
+		Time:       time.Now().UTC(),
+		Items:      2,
+		FreedBytes: 60 * gib,
+		Top: []notify.Item{
+			{Title: "Example Movie (2019)", Type: "movie", Action: "delete", Bytes: 45 * gib},
+			{Title: "Example Show", Type: "series", Action: "delete", Bytes: 15 * gib},
+		},
+		Results:  &notify.Results{Done: 2, Failed: 1},
+		Failures: []string{"series \"Another Show\": this is a test failure"},
+	}
+}
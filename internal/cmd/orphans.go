@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/scan"
+
+	"github.com/spf13/cobra"
+)
+
+var orphansOut string
+var orphansCSV string
+var orphansTable bool
+var orphansAction string
+
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "Report files in Radarr/Sonarr root folders that neither service tracks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signalContext()
+		defer cancel()
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		// NormalizeAction turns "" into delete; an empty --action must not
+		// plan every orphan for deletion.
+		if strings.TrimSpace(orphansAction) == "" {
+			return fmt.Errorf("--action must be keep, delete, quarantine or ignore")
+		}
+		action, err := report.NormalizeAction(orphansAction)
+		if err != nil {
+			return fmt.Errorf("--action: %w", err)
+		}
+		switch action {
+		case report.ActionKeep, report.ActionDelete, report.ActionQuarantine, report.ActionIgnore:
+		default:
+			return fmt.Errorf("--action must be keep, delete, quarantine or ignore")
+		}
+
+		rep, err := scan.FindOrphans(ctx, cfg, scan.OrphanOptions{Action: action})
+		if err != nil {
+			return err
+		}
+
+		if orphansOut != "" {
+			if err := report.WriteJSON(orphansOut, rep); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote report to %s (%d items)\n", orphansOut, len(rep.Items))
+		}
+		if orphansCSV != "" {
+			if err := report.WriteCSV(orphansCSV, rep); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote CSV to %s (%d items)\n", orphansCSV, len(rep.Items))
+		}
+		if orphansTable {
+			report.PrintTable(rep)
+		}
+
+		summary := report.Summarize(rep)
+		fmt.Printf("%d orphans (%s GiB on disk, %s GiB reclaimable)\n", summary.Total, formatGiB(summary.TotalBytes), formatGiB(summary.ReclaimableBytes))
+		printDiskSummary(summary)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(orphansCmd)
+	orphansCmd.Flags().StringVar(&orphansOut, "out", "orphans.json", "Output path for the orphans report")
+	orphansCmd.Flags().StringVar(&orphansCSV, "csv", "", "Optional CSV output path for review")
+	orphansCmd.Flags().BoolVar(&orphansTable, "table", false, "Print a pretty table of results to stdout")
+	orphansCmd.Flags().StringVar(&orphansAction, "action", report.ActionKeep, "Planned action for every orphan: keep, delete, quarantine or ignore")
+}
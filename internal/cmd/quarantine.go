@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"go-unraid-clean/internal/apply"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/quarantine"
+	"go-unraid-clean/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var purgeOlderThanDays int
+var purgeConfirm bool
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete quarantined items older than N days",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		days := purgeOlderThanDays
+		if !cmd.Flags().Changed("older-than-days") {
+			days = cfg.Quarantine.PurgeAfterDays
+		}
+
+		entries, err := apply.PurgeQuarantine(cfg, time.Duration(days)*24*time.Hour, !purgeConfirm)
+		printQuarantine(entries)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		if !purgeConfirm {
+			fmt.Println("Review complete. Re-run with --confirm to permanently delete these items.")
+			return nil
+		}
+		fmt.Printf("Purged %d items.\n", len(entries))
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [id...]",
+	Short: "Move quarantined items back and re-add them to Sonarr/Radarr",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			store, err := quarantine.NewStore(state.FromConfig(cfg).QuarantinePath())
+			if err != nil {
+				return err
+			}
+			entries, err := store.List()
+			if err != nil {
+				return err
+			}
+			printQuarantine(entries)
+			return nil
+		}
+
+		var errs []error
+		for _, id := range args {
+			entry, err := apply.RestoreQuarantine(ctx, cfg, id)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			fmt.Printf("Restored %s to %s\n", entry.Title, entry.FilesPath())
+		}
+		return errors.Join(errs...)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+	rootCmd.AddCommand(restoreCmd)
+	purgeCmd.Flags().IntVar(&purgeOlderThanDays, "older-than-days", 30, "Purge items quarantined at least this many days ago (defaults to quarantine.purge_after_days)")
+	purgeCmd.Flags().BoolVar(&purgeConfirm, "confirm", false, "Actually delete quarantined items")
+}
+
+func printQuarantine(entries []quarantine.Entry) {
+	if len(entries) == 0 {
+		fmt.Println("No quarantined items.")
+		return
+	}
+	now := time.Now().UTC()
+	w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tTITLE\tSIZE(GiB)\tQUARANTINED\tAGE_DAYS\tORIGINAL_PATH")
+	for _, entry := range entries {
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\t%.1f\t%s\n",
+			entry.ID,
+			entry.Type,
+			entry.Title,
+			formatGiB(entry.SizeBytes),
+			entry.QuarantinedAt.Format(time.RFC3339),
+			now.Sub(entry.QuarantinedAt).Hours()/24,
+			entry.OriginalPath,
+		)
+	}
+	_ = w.Flush()
+}
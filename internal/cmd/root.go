@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"go-unraid-clean/internal/logging"
 
@@ -33,3 +36,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "config.yaml", "Path to config file")
 	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Increase verbosity (-v for debug, -vv for trace)")
 }
+
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/scan"
+
+	"github.com/spf13/cobra"
+)
+
+var rulesTestWhen string
+var rulesTestName string
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Inspect custom rule expressions",
+}
+
+var rulesTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Evaluate a rule against the current library without writing a report",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signalContext()
+		defer cancel()
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		when := rulesTestWhen
+		if rulesTestName != "" {
+			if when != "" {
+				return fmt.Errorf("use either --when or --name, not both")
+			}
+			for _, rule := range cfg.Rules.Custom {
+				if rule.Name == rulesTestName {
+					when = rule.When
+					break
+				}
+			}
+			if when == "" {
+				return fmt.Errorf("custom rule %q not found in config", rulesTestName)
+			}
+		}
+		if when == "" {
+			return fmt.Errorf("--when or --name is required")
+		}
+
+		rep, err := scan.TestRule(ctx, cfg, when)
+		if err != nil {
+			return err
+		}
+		if len(rep.Items) == 0 {
+			fmt.Println("No items match.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "TYPE\tTITLE\tSIZE(GiB)\tWATCH_HOURS\tVIEWERS\tREASON\tSCORE\tPATH")
+		for _, item := range rep.Items {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.1f\t%d\t%s\t%.2f\t%s\n",
+				item.Type,
+				item.Title,
+				formatGiB(item.SizeBytes),
+				item.TotalWatchHours,
+				item.Viewers,
+				item.Reason,
+				item.Score,
+				item.Path,
+			)
+		}
+		_ = w.Flush()
+		summary := report.Summarize(rep)
+		fmt.Printf("%d items match (%s GiB)\n", summary.Total, formatGiB(summary.TotalBytes))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+	rulesCmd.AddCommand(rulesTestCmd)
+	rulesTestCmd.Flags().StringVar(&rulesTestWhen, "when", "", "Expression to evaluate, e.g. \"type == 'movie' && size_gib > 40\"")
+	rulesTestCmd.Flags().StringVar(&rulesTestName, "name", "", "Name of a custom rule from the config to evaluate")
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 
 	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/notify"
 	"go-unraid-clean/internal/report"
 	"go-unraid-clean/internal/scan"
+	"go-unraid-clean/internal/unraid"
 
 	"github.com/spf13/cobra"
 )
@@ -16,6 +18,10 @@ var scanCSV string
 var scanTable bool
 var scanSort string
 var scanOrder string
+var scanReclaim string
+var scanUntilFree string
+var scanFreePath string
+var scanDisks []string
 
 var scanCmd = &cobra.Command{
 	Use:   "scan",
@@ -28,9 +34,34 @@ var scanCmd = &cobra.Command{
 			return err
 		}
 
+		reclaimValue := cfg.Rules.ReclaimTarget
+		if scanReclaim != "" {
+			reclaimValue = scanReclaim
+		}
+		reclaimBytes, err := config.ParseSize(reclaimValue)
+		if err != nil {
+			return fmt.Errorf("--reclaim: %w", err)
+		}
+		untilFreeValue := cfg.Rules.ReclaimUntilFree
+		if scanUntilFree != "" {
+			untilFreeValue = scanUntilFree
+		}
+		untilFree, err := config.ParsePercent(untilFreeValue)
+		if err != nil {
+			return fmt.Errorf("--until-free: %w", err)
+		}
+		freePath := cfg.Rules.FreeSpacePath
+		if scanFreePath != "" {
+			freePath = scanFreePath
+		}
+
 		rep, err := scan.Run(ctx, cfg, scan.Options{
-			SortBy:    scanSort,
-			SortOrder: scanOrder,
+			SortBy:           scanSort,
+			SortOrder:        scanOrder,
+			ReclaimBytes:     reclaimBytes,
+			UntilFreePercent: untilFree,
+			FreeSpacePath:    freePath,
+			Disks:            scanDisks,
 		})
 		if err != nil {
 			return err
@@ -53,6 +84,14 @@ var scanCmd = &cobra.Command{
 		if scanTable {
 			report.PrintTable(rep)
 		}
+
+		if rep.ReclaimTargetBytes > 0 {
+			summary := report.Summarize(rep)
+			fmt.Printf("Reclaim target: %s GiB, selected: %s GiB reclaimable (%s GiB on disk) in %d items\n", formatGiB(rep.ReclaimTargetBytes), formatGiB(summary.ReclaimableBytes), formatGiB(summary.TotalBytes), summary.Total)
+		}
+		printDiskSummary(report.Summarize(rep))
+
+		notify.Send(ctx, cfg.Notify, notify.ScanSummary(rep, scanOut, cfg.Notify.Top))
 		return nil
 	},
 }
@@ -62,6 +101,25 @@ func init() {
 	scanCmd.Flags().StringVar(&scanOut, "out", "review.json", "Output path for the review report")
 	scanCmd.Flags().StringVar(&scanCSV, "csv", "", "Optional CSV output path for review")
 	scanCmd.Flags().BoolVar(&scanTable, "table", false, "Print a pretty table of results to stdout")
-	scanCmd.Flags().StringVar(&scanSort, "sort", "size", "Sort by: size, added, gap, last_activity, inactivity")
+	scanCmd.Flags().StringVar(&scanSort, "sort", "size", "Sort by: size, added, gap, last_activity, inactivity, score")
 	scanCmd.Flags().StringVar(&scanOrder, "order", "desc", "Sort order: asc or desc")
+	scanCmd.Flags().StringVar(&scanReclaim, "reclaim", "", "Only select the highest-ranked items needed to free this much space (e.g. 2TB, 500GiB)")
+	scanCmd.Flags().StringVar(&scanUntilFree, "until-free", "", "Select items until this percentage of the media share is free (e.g. 10%)")
+	scanCmd.Flags().StringVar(&scanFreePath, "free-path", "", "Path of the media share to check free space for --until-free")
+	scanCmd.Flags().StringSliceVar(&scanDisks, "disk", nil, "Only flag items with files on these Unraid disks (e.g. disk3, cache); repeatable")
+}
+
+func printDiskSummary(summary report.Summary) {
+	if len(summary.ByDisk) == 0 {
+		return
+	}
+	disks := make([]string, 0, len(summary.ByDisk))
+	for disk := range summary.ByDisk {
+		disks = append(disks, disk)
+	}
+	unraid.SortDisks(disks)
+	fmt.Println("By disk:")
+	for _, disk := range disks {
+		fmt.Printf("  %-8s %s GiB\n", disk, formatGiB(summary.ByDisk[disk]))
+	}
 }
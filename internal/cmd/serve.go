@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/daemon"
+	"go-unraid-clean/internal/web"
+
+	"github.com/spf13/cobra"
+)
+
+var serveSchedule string
+var serveRunNow bool
+
+var serveCmd = &cobra.Command{
+	Use:     "serve",
+	Aliases: []string{"daemon"},
+	Short:   "Run scans on a schedule and keep reports in the state directory",
+	Long:    "Run scans on a schedule and keep reports in the state directory. When web.listen is set, the review UI and JSON API are served alongside.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signalContext()
+		defer stop()
+
+		opts := daemon.Options{
+			ConfigPath: configPath,
+			Schedule:   serveSchedule,
+			RunNow:     serveRunNow,
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if cfg.Web.Listen == "" {
+			return daemon.Run(ctx, opts)
+		}
+
+		if err := web.CheckExposure(cfg.Web.Listen, cfg.Web); err != nil {
+			return err
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		handler, err := httpHandler(ctx, cfg, web.Options{ConfigPath: configPath})
+		if err != nil {
+			return err
+		}
+		webErr := make(chan error, 1)
+		go func() {
+			webErr <- web.ListenAndServe(ctx, cfg.Web.Listen, handler)
+			// Stop the daemon too if the UI cannot listen.
+			cancel()
+		}()
+		runErr := daemon.Run(ctx, opts)
+		cancel()
+		if err := <-webErr; err != nil {
+			return err
+		}
+		return runErr
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveSchedule, "schedule", "", "Cron schedule overriding serve.schedule (e.g. \"0 3 * * *\" or \"@every 12h\")")
+	serveCmd.Flags().BoolVar(&serveRunNow, "run-now", false, "Scan once at startup before waiting for the schedule")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+
+	"go-unraid-clean/internal/api"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/web"
+
+	"github.com/spf13/cobra"
+)
+
+var webListen string
+var webIn string
+
+var webCmd = &cobra.Command{
+	Use:   "web",
+	Short: "Serve a browser UI and JSON API for reviewing and applying reports",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signalContext()
+		defer stop()
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		listen := cfg.Web.Listen
+		if webListen != "" {
+			listen = webListen
+		}
+		if listen == "" {
+			listen = ":8080"
+		}
+		if err := web.CheckExposure(listen, cfg.Web); err != nil {
+			return err
+		}
+
+		handler, err := httpHandler(ctx, cfg, web.Options{ConfigPath: configPath, ReportPath: webIn})
+		if err != nil {
+			return err
+		}
+		return web.ListenAndServe(ctx, listen, handler)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(webCmd)
+	webCmd.Flags().StringVar(&webListen, "listen", "", "Address to listen on (default web.listen, then :8080)")
+	webCmd.Flags().StringVar(&webIn, "in", "", "JSON report to review instead of the newest scan report in the state directory")
+}
+
+// httpHandler serves the web UI at / and the JSON API under /api.
+func httpHandler(ctx context.Context, cfg config.Config, opts web.Options) (http.Handler, error) {
+	ui, err := web.New(opts, cfg.Web)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", ui.Handler())
+	mux.Handle("/api/", http.StripPrefix("/api", api.New(ctx, configPath, cfg.API).Handler()))
+	return mux, nil
+}
@@ -3,74 +3,462 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"strings"
+
+	"go-unraid-clean/internal/expr"
+	"go-unraid-clean/internal/schedule"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Tautulli   Service    `yaml:"tautulli"`
-	Sonarr     Service    `yaml:"sonarr"`
-	Radarr     Service    `yaml:"radarr"`
-	Rules      Rules      `yaml:"rules"`
-	Exceptions Exceptions `yaml:"exceptions"`
+	Tautulli       Service      `yaml:"tautulli"`
+	Plex           Service      `yaml:"plex"`
+	Jellyfin       Service      `yaml:"jellyfin"`
+	Emby           Service      `yaml:"emby"`
+	Overseerr      Overseerr    `yaml:"overseerr"`
+	ActivitySource string       `yaml:"activity_source"`
+	Sonarr         Instances    `yaml:"sonarr"`
+	Radarr         Instances    `yaml:"radarr"`
+	Torrent        Torrent      `yaml:"torrent"`
+	PathMappings   PathMappings `yaml:"path_mappings"`
+	Unraid         Unraid       `yaml:"unraid"`
+	Rules          Rules        `yaml:"rules"`
+	Exceptions     Exceptions   `yaml:"exceptions"`
+	StateDir       string       `yaml:"state_dir"`
+	Journal        Journal      `yaml:"journal"`
+	Quarantine     Quarantine   `yaml:"quarantine"`
+	Serve          Serve        `yaml:"serve"`
+	LeavingSoon    LeavingSoon  `yaml:"leaving_soon"`
+	Web            Web          `yaml:"web"`
+	API            API          `yaml:"api"`
+	Notify         Notify       `yaml:"notify"`
 }
 
+const (
+	ActivitySourceTautulli = "tautulli"
+	ActivitySourcePlex     = "plex"
+	ActivitySourceJellyfin = "jellyfin"
+	ActivitySourceEmby     = "emby"
+	ActivitySourceBoth     = "both"
+)
+
 type Service struct {
 	BaseURL string `yaml:"base_url"`
 	APIKey  string `yaml:"api_key"`
 }
 
+// Overseerr configures Overseerr/Jellyseerr request awareness. ProtectDays
+// keeps requested items out of the report for that long after the request;
+// RequesterGraceDays only allows deletion once the requester has watched the
+// item or that many days have passed.
+type Overseerr struct {
+	Service            `yaml:",inline"`
+	ProtectDays        int  `yaml:"protect_days"`
+	RequesterGraceDays int  `yaml:"requester_grace_days"`
+	ClearOnDelete      bool `yaml:"clear_on_delete"`
+}
+
+// Torrent configures the download client that seeds the files Radarr and
+// Sonarr imported. Torrents are removed with their data on delete only when
+// RemoveOnDelete is set and MinRatio or MinSeedDays has been reached.
+type Torrent struct {
+	Client         string  `yaml:"client"`
+	BaseURL        string  `yaml:"base_url"`
+	Username       string  `yaml:"username"`
+	Password       string  `yaml:"password"`
+	RemoveOnDelete bool    `yaml:"remove_on_delete"`
+	MinRatio       float64 `yaml:"min_ratio"`
+	MinSeedDays    float64 `yaml:"min_seed_days"`
+}
+
+const (
+	TorrentClientQBittorrent  = "qbittorrent"
+	TorrentClientTransmission = "transmission"
+	TorrentClientDeluge       = "deluge"
+)
+
+// GoalMet reports whether a torrent has seeded enough to be removed: either
+// threshold is sufficient, and with neither set every torrent qualifies.
+func (t Torrent) GoalMet(ratio float64, seedingSeconds int64) bool {
+	if t.MinRatio <= 0 && t.MinSeedDays <= 0 {
+		return true
+	}
+	if t.MinRatio > 0 && ratio >= t.MinRatio {
+		return true
+	}
+	return t.MinSeedDays > 0 && float64(seedingSeconds) >= t.MinSeedDays*86400
+}
+
+// PathMapping translates a path prefix as Radarr/Sonarr report it (usually a
+// container path) to where the same files live on this host.
+type PathMapping struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// PathMappings holds the mappings for each service that reports paths.
+type PathMappings struct {
+	Radarr []PathMapping `yaml:"radarr"`
+	Sonarr []PathMapping `yaml:"sonarr"`
+}
+
+// Unraid locates user share files on the array disks and pools mounted
+// under MountRoot.
+type Unraid struct {
+	MountRoot string `yaml:"mount_root"`
+}
+
+type Journal struct {
+	Path string `yaml:"path"`
+}
+
+type Quarantine struct {
+	Path           string `yaml:"path"`
+	PurgeAfterDays int    `yaml:"purge_after_days"`
+}
+
+// Serve configures the serve daemon, which scans on Schedule (cron syntax or
+// "@every <duration>") and keeps the newest KeepReports reports in the state
+// directory.
+type Serve struct {
+	Schedule    string    `yaml:"schedule"`
+	KeepReports int       `yaml:"keep_reports"`
+	AutoApply   AutoApply `yaml:"auto_apply"`
+}
+
+// AutoApply lets the daemon apply items that were flagged in more than
+// GraceScans consecutive scans. Action replaces each item's planned action
+// when set.
+type AutoApply struct {
+	Enabled    bool   `yaml:"enabled"`
+	GraceScans int    `yaml:"grace_scans"`
+	Action     string `yaml:"action"`
+}
+
+// LeavingSoon puts items flagged by Scans consecutive scheduled scans into a
+// Plex collection and holds back deleting them until they have spent
+// GraceDays there without being watched.
+type LeavingSoon struct {
+	Enabled    bool   `yaml:"enabled"`
+	Collection string `yaml:"collection"`
+	Scans      int    `yaml:"scans"`
+	GraceDays  int    `yaml:"grace_days"`
+}
+
+// Web configures the review UI. Listen is the web command's default address
+// and makes serve start the UI alongside the daemon; Username and Password
+// turn on basic auth.
+type Web struct {
+	Listen   string `yaml:"listen"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// API configures the JSON API served under /api by the web and serve
+// commands. It stays disabled until Token is set.
+type API struct {
+	Token string `yaml:"token"`
+}
+
+// Notify sends a summary to every sink when a scan, apply or interactive
+// review finishes. Events limits which of those notify (all when empty) and
+// Top is how many of the largest items a summary lists.
+type Notify struct {
+	Events []string     `yaml:"events"`
+	Top    int          `yaml:"top"`
+	Sinks  []NotifySink `yaml:"sinks"`
+}
+
+// NotifySink is one notification target; Type selects which fields apply.
+// webhook posts Body, a text/template rendered with the summary, or the
+// summary as JSON when Body is empty. discord posts an embed to URL. ntfy
+// publishes to Topic on URL, gotify to URL with the application Token, and
+// smtp mails From to To through Host.
+type NotifySink struct {
+	Name     string            `yaml:"name"`
+	Type     string            `yaml:"type"`
+	URL      string            `yaml:"url"`
+	Headers  map[string]string `yaml:"headers"`
+	Body     string            `yaml:"body"`
+	Topic    string            `yaml:"topic"`
+	Token    string            `yaml:"token"`
+	Priority int               `yaml:"priority"`
+	Host     string            `yaml:"host"`
+	Port     int               `yaml:"port"`
+	Username string            `yaml:"username"`
+	Password string            `yaml:"password"`
+	From     string            `yaml:"from"`
+	To       []string          `yaml:"to"`
+}
+
+const (
+	NotifySinkWebhook = "webhook"
+	NotifySinkDiscord = "discord"
+	NotifySinkNtfy    = "ntfy"
+	NotifySinkGotify  = "gotify"
+	NotifySinkSMTP    = "smtp"
+
+	NotifyEventScan        = "scan"
+	NotifyEventApply       = "apply"
+	NotifyEventInteractive = "interactive"
+)
+
+// Notifies reports whether event should be sent to the sinks.
+func (n Notify) Notifies(event string) bool {
+	if len(n.Sinks) == 0 {
+		return false
+	}
+	if len(n.Events) == 0 {
+		return true
+	}
+	for _, e := range n.Events {
+		if strings.EqualFold(strings.TrimSpace(e), event) {
+			return true
+		}
+	}
+	return false
+}
+
 type Rules struct {
-	ActivityMinPercent         int     `yaml:"activity_min_percent"`
-	InactivityDaysAfterWatch   int     `yaml:"inactivity_days_after_watch"`
-	NeverWatchedDaysSinceAdded int     `yaml:"never_watched_days_since_added"`
-	LowWatchMinAddedDays       int     `yaml:"low_watch_min_added_days"`
-	LowWatchMaxHours           float64 `yaml:"low_watch_max_hours"`
-	LowWatchRequire            bool    `yaml:"low_watch_require"`
-	SeriesEndedOnly            bool    `yaml:"series_ended_only"`
+	ActivityMinPercent         int          `yaml:"activity_min_percent"`
+	InactivityDaysAfterWatch   int          `yaml:"inactivity_days_after_watch"`
+	NeverWatchedDaysSinceAdded int          `yaml:"never_watched_days_since_added"`
+	LowWatchMinAddedDays       int          `yaml:"low_watch_min_added_days"`
+	LowWatchMaxHours           float64      `yaml:"low_watch_max_hours"`
+	LowWatchRequire            bool         `yaml:"low_watch_require"`
+	SeriesEndedOnly            bool         `yaml:"series_ended_only"`
+	ReclaimTarget              string       `yaml:"reclaim_target"`
+	ReclaimUntilFree           string       `yaml:"reclaim_until_free"`
+	FreeSpacePath              string       `yaml:"free_space_path"`
+	Scoring                    Scoring      `yaml:"scoring"`
+	Custom                     []CustomRule `yaml:"custom"`
+	Seasons                    Seasons      `yaml:"seasons"`
+	InProgress                 InProgress   `yaml:"in_progress"`
+}
+
+const (
+	InProgressModeExclude = "exclude"
+	InProgressModeMark    = "mark"
+)
+
+// InProgress protects items that are streaming now, are on Plex's Continue
+// Watching, or were partially watched within WindowDays.
+type InProgress struct {
+	Enabled    bool   `yaml:"enabled"`
+	WindowDays int    `yaml:"window_days"`
+	Mode       string `yaml:"mode"`
+}
+
+// Seasons enables season-level candidates for series that are not flagged as
+// a whole.
+type Seasons struct {
+	Enabled   bool `yaml:"enabled"`
+	Unmonitor bool `yaml:"unmonitor"`
+}
+
+const (
+	RuleEffectFlag    = "flag"
+	RuleEffectExclude = "exclude"
+)
+
+// CustomRule is an expression evaluated against each item's fields. Flag
+// rules mark matching items for review; exclude rules keep them out of the
+// report even when the built-in rules match.
+type CustomRule struct {
+	Name   string `yaml:"name"`
+	When   string `yaml:"when"`
+	Effect string `yaml:"effect"`
+}
+
+type Scoring struct {
+	InactivityDays   float64 `yaml:"inactivity_days"`
+	NeverWatchedAge  float64 `yaml:"never_watched_age"`
+	WatchHoursPerGiB float64 `yaml:"watch_hours_per_gib"`
+	DistinctViewers  float64 `yaml:"distinct_viewers"`
+	SeriesEnded      float64 `yaml:"series_ended"`
+	SizeGiB          float64 `yaml:"size_gib"`
+
+	// set records that the weights were configured or defaulted, so an
+	// explicit all-zero block is not replaced with the defaults.
+	set bool
+}
+
+// UnmarshalYAML fills in the default of every weight the block leaves out, so
+// tuning one weight keeps the others. A weight set to 0 stays 0.
+func (s *Scoring) UnmarshalYAML(node *yaml.Node) error {
+	type plain Scoring
+	weights := plain(DefaultScoring())
+	if err := node.Decode(&weights); err != nil {
+		return err
+	}
+	*s = Scoring(weights)
+	s.set = true
+	return nil
 }
 
+func DefaultScoring() Scoring {
+	return Scoring{
+		InactivityDays:   1.0 / 30,
+		NeverWatchedAge:  1.0 / 30,
+		WatchHoursPerGiB: -2,
+		DistinctViewers:  -0.5,
+		SeriesEnded:      1,
+		SizeGiB:          0.1,
+	}
+}
+
+// Exceptions keep items out of the report. Movies and Series apply to every
+// instance, except radarr_ids and sonarr_ids which only identify items in the
+// first instance; Instances holds exceptions scoped to one named instance.
 type Exceptions struct {
-	Movies MovieExceptions  `yaml:"movies"`
-	Series SeriesExceptions `yaml:"series"`
+	Movies    MovieExceptions               `yaml:"movies" json:"movies"`
+	Series    SeriesExceptions              `yaml:"series" json:"series"`
+	Orphans   OrphanExceptions              `yaml:"orphans" json:"orphans"`
+	Instances map[string]InstanceExceptions `yaml:"instances,omitempty" json:"instances,omitempty"`
+}
+
+// InstanceExceptions are the exceptions of one Radarr or Sonarr instance.
+type InstanceExceptions struct {
+	Movies MovieExceptions  `yaml:"movies,omitempty" json:"movies,omitempty"`
+	Series SeriesExceptions `yaml:"series,omitempty" json:"series,omitempty"`
+}
+
+// OrphanExceptions lists host paths the orphans command never reports.
+type OrphanExceptions struct {
+	PathPrefixes []string `yaml:"path_prefixes" json:"path_prefixes"`
 }
 
 type MovieExceptions struct {
-	RadarrIDs    []int    `yaml:"radarr_ids"`
-	TMDBIDs      []int    `yaml:"tmdb_ids"`
-	IMDBIDs      []string `yaml:"imdb_ids"`
-	Titles       []string `yaml:"titles"`
-	PathPrefixes []string `yaml:"path_prefixes"`
+	RadarrIDs    []int    `yaml:"radarr_ids" json:"radarr_ids"`
+	TMDBIDs      []int    `yaml:"tmdb_ids" json:"tmdb_ids"`
+	IMDBIDs      []string `yaml:"imdb_ids" json:"imdb_ids"`
+	Titles       []string `yaml:"titles" json:"titles"`
+	PathPrefixes []string `yaml:"path_prefixes" json:"path_prefixes"`
 }
 
 type SeriesExceptions struct {
-	SonarrIDs    []int    `yaml:"sonarr_ids"`
-	TVDBIDs      []int    `yaml:"tvdb_ids"`
-	IMDBIDs      []string `yaml:"imdb_ids"`
-	Titles       []string `yaml:"titles"`
-	PathPrefixes []string `yaml:"path_prefixes"`
+	SonarrIDs    []int    `yaml:"sonarr_ids" json:"sonarr_ids"`
+	TVDBIDs      []int    `yaml:"tvdb_ids" json:"tvdb_ids"`
+	IMDBIDs      []string `yaml:"imdb_ids" json:"imdb_ids"`
+	Titles       []string `yaml:"titles" json:"titles"`
+	PathPrefixes []string `yaml:"path_prefixes" json:"path_prefixes"`
 }
 
 func (c *Config) ApplyDefaults() {
+	if c.ActivitySource == "" {
+		c.ActivitySource = ActivitySourceTautulli
+	}
 	if c.Rules.ActivityMinPercent == 0 {
 		c.Rules.ActivityMinPercent = 1
 	}
+	applyInstanceDefaults("radarr", c.Radarr)
+	applyInstanceDefaults("sonarr", c.Sonarr)
+	if c.Unraid.MountRoot == "" {
+		c.Unraid.MountRoot = "/mnt"
+	}
 	if c.Rules.InactivityDaysAfterWatch == 0 {
 		c.Rules.InactivityDaysAfterWatch = 30
 	}
 	if c.Rules.NeverWatchedDaysSinceAdded == 0 {
 		c.Rules.NeverWatchedDaysSinceAdded = 180
 	}
+	// An omitted scoring block is never decoded; a present one is defaulted
+	// per weight by Scoring.UnmarshalYAML.
+	if !c.Rules.Scoring.set {
+		c.Rules.Scoring = DefaultScoring()
+		c.Rules.Scoring.set = true
+	}
+	if c.Rules.InProgress.WindowDays == 0 {
+		c.Rules.InProgress.WindowDays = 14
+	}
+	if c.Rules.InProgress.Mode == "" {
+		c.Rules.InProgress.Mode = InProgressModeExclude
+	}
+	for idx := range c.Rules.Custom {
+		if c.Rules.Custom[idx].Effect == "" {
+			c.Rules.Custom[idx].Effect = RuleEffectFlag
+		}
+	}
+	if c.Journal.Path == "" {
+		c.Journal.Path = "journal.jsonl"
+	}
+	if c.Quarantine.PurgeAfterDays == 0 {
+		c.Quarantine.PurgeAfterDays = 30
+	}
+	if c.Serve.Schedule == "" {
+		c.Serve.Schedule = "0 3 * * *"
+	}
+	if c.Serve.KeepReports == 0 {
+		c.Serve.KeepReports = 14
+	}
+	if c.Serve.AutoApply.GraceScans == 0 {
+		c.Serve.AutoApply.GraceScans = 3
+	}
+	if c.LeavingSoon.Collection == "" {
+		c.LeavingSoon.Collection = "Leaving Soon"
+	}
+	if c.LeavingSoon.Scans == 0 {
+		c.LeavingSoon.Scans = 2
+	}
+	if c.LeavingSoon.GraceDays == 0 {
+		c.LeavingSoon.GraceDays = 14
+	}
+	if c.Notify.Top == 0 {
+		c.Notify.Top = 10
+	}
+	for idx := range c.Notify.Sinks {
+		sink := &c.Notify.Sinks[idx]
+		sink.Type = strings.ToLower(strings.TrimSpace(sink.Type))
+		if sink.Name == "" {
+			sink.Name = sink.Type
+		}
+		switch {
+		case sink.Type == NotifySinkNtfy && sink.URL == "":
+			sink.URL = "https://ntfy.sh"
+		case sink.Type == NotifySinkSMTP && sink.Port == 0:
+			sink.Port = 587
+		}
+	}
 }
 
 func (c Config) Validate() error {
-	if err := validateService("tautulli", c.Tautulli); err != nil {
-		return err
+	sources := c.ActivitySources()
+	if len(sources) == 0 {
+		return fmt.Errorf("activity_source must be a comma-separated list of %s, %s, %s, %s (or %s)", ActivitySourceTautulli, ActivitySourcePlex, ActivitySourceJellyfin, ActivitySourceEmby, ActivitySourceBoth)
 	}
-	if err := validateService("sonarr", c.Sonarr); err != nil {
+	for _, source := range sources {
+		switch source {
+		case ActivitySourceTautulli:
+			if err := validateService("tautulli", c.Tautulli); err != nil {
+				return err
+			}
+		case ActivitySourcePlex:
+			if err := validateService("plex", c.Plex); err != nil {
+				return err
+			}
+		case ActivitySourceJellyfin:
+			if err := validateService("jellyfin", c.Jellyfin); err != nil {
+				return err
+			}
+		case ActivitySourceEmby:
+			if err := validateService("emby", c.Emby); err != nil {
+				return err
+			}
+		}
+	}
+	names := map[string]string{}
+	if err := validateInstances("sonarr", c.Sonarr, names); err != nil {
 		return err
 	}
-	if err := validateService("radarr", c.Radarr); err != nil {
+	if err := validateInstances("radarr", c.Radarr, names); err != nil {
 		return err
 	}
+	for name := range c.Exceptions.Instances {
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("exceptions: instances.%s does not name a configured radarr or sonarr instance", name)
+		}
+	}
 	if c.Rules.ActivityMinPercent <= 0 {
 		return fmt.Errorf("rules: activity_min_percent must be positive")
 	}
@@ -80,6 +468,9 @@ func (c Config) Validate() error {
 	if c.Rules.NeverWatchedDaysSinceAdded <= 0 {
 		return fmt.Errorf("rules: never-watched days must be positive")
 	}
+	if c.Quarantine.PurgeAfterDays < 0 {
+		return fmt.Errorf("quarantine: purge_after_days must be non-negative")
+	}
 	if c.Rules.LowWatchMinAddedDays < 0 || c.Rules.LowWatchMaxHours < 0 {
 		return fmt.Errorf("rules: low watch thresholds must be non-negative")
 	}
@@ -87,6 +478,213 @@ func (c Config) Validate() error {
 		(c.Rules.LowWatchMaxHours > 0 && c.Rules.LowWatchMinAddedDays <= 0) {
 		return fmt.Errorf("rules: low_watch_min_added_days and low_watch_max_hours must both be set to enable")
 	}
+	if _, err := ParseSize(c.Rules.ReclaimTarget); err != nil {
+		return fmt.Errorf("rules: reclaim_target: %w", err)
+	}
+	if _, err := ParsePercent(c.Rules.ReclaimUntilFree); err != nil {
+		return fmt.Errorf("rules: reclaim_until_free: %w", err)
+	}
+	if c.Rules.ReclaimUntilFree != "" && c.Rules.FreeSpacePath == "" {
+		return fmt.Errorf("rules: reclaim_until_free requires free_space_path")
+	}
+	if c.Overseerr.BaseURL != "" {
+		if err := validateService("overseerr", c.Overseerr.Service); err != nil {
+			return err
+		}
+	}
+	if c.Overseerr.ProtectDays < 0 || c.Overseerr.RequesterGraceDays < 0 {
+		return fmt.Errorf("overseerr: protect_days and requester_grace_days must be non-negative")
+	}
+	if err := validateTorrent(c.Torrent); err != nil {
+		return err
+	}
+	if err := validatePathMappings("path_mappings.radarr", c.PathMappings.Radarr); err != nil {
+		return err
+	}
+	if err := validatePathMappings("path_mappings.sonarr", c.PathMappings.Sonarr); err != nil {
+		return err
+	}
+	if c.Rules.InProgress.WindowDays < 0 {
+		return fmt.Errorf("rules: in_progress.window_days must be non-negative")
+	}
+	switch c.Rules.InProgress.Mode {
+	case InProgressModeExclude, InProgressModeMark:
+	default:
+		return fmt.Errorf("rules: in_progress.mode must be %s or %s", InProgressModeExclude, InProgressModeMark)
+	}
+	if err := validateCustomRules(c.Rules.Custom); err != nil {
+		return err
+	}
+	if err := validateServe(c.Serve, c.Quarantine); err != nil {
+		return err
+	}
+	if c.LeavingSoon.Enabled {
+		if err := validateService("plex", c.Plex); err != nil {
+			return fmt.Errorf("leaving_soon: %w", err)
+		}
+		if c.LeavingSoon.Scans < 1 {
+			return fmt.Errorf("leaving_soon: scans must be at least 1")
+		}
+		if c.LeavingSoon.GraceDays < 0 {
+			return fmt.Errorf("leaving_soon: grace_days must not be negative")
+		}
+	}
+	if (c.Web.Username == "") != (c.Web.Password == "") {
+		return fmt.Errorf("web: username and password must be set together")
+	}
+	if err := validateNotify(c.Notify); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateServe(s Serve, q Quarantine) error {
+	if _, err := schedule.Parse(s.Schedule); err != nil {
+		return fmt.Errorf("serve: %w", err)
+	}
+	if s.KeepReports < 1 {
+		return fmt.Errorf("serve: keep_reports must be at least 1")
+	}
+	if s.AutoApply.GraceScans < 1 {
+		return fmt.Errorf("serve: auto_apply.grace_scans must be at least 1")
+	}
+	switch s.AutoApply.Action {
+	case "", "delete", "delete_files":
+	case "quarantine":
+		if q.Path == "" {
+			return fmt.Errorf("serve: auto_apply.action quarantine requires quarantine.path")
+		}
+	default:
+		return fmt.Errorf("serve: auto_apply.action must be delete, delete_files or quarantine")
+	}
+	return nil
+}
+
+func validateNotify(n Notify) error {
+	for _, event := range n.Events {
+		switch strings.ToLower(strings.TrimSpace(event)) {
+		case NotifyEventScan, NotifyEventApply, NotifyEventInteractive:
+		default:
+			return fmt.Errorf("notify: events must be %s, %s or %s", NotifyEventScan, NotifyEventApply, NotifyEventInteractive)
+		}
+	}
+	if n.Top < 1 {
+		return fmt.Errorf("notify: top must be at least 1")
+	}
+	names := map[string]bool{}
+	for idx, sink := range n.Sinks {
+		if names[sink.Name] {
+			return fmt.Errorf("notify: sink %q is defined more than once; give each sink a unique name", sink.Name)
+		}
+		names[sink.Name] = true
+		field := fmt.Sprintf("notify: sinks[%d]", idx)
+		switch sink.Type {
+		case NotifySinkWebhook, NotifySinkDiscord, NotifySinkNtfy, NotifySinkGotify:
+			if sink.URL == "" {
+				return fmt.Errorf("%s: url is required", field)
+			}
+			if _, err := url.ParseRequestURI(sink.URL); err != nil {
+				return fmt.Errorf("%s: url is invalid: %w", field, err)
+			}
+		case NotifySinkSMTP:
+			if sink.Host == "" || sink.From == "" || len(sink.To) == 0 {
+				return fmt.Errorf("%s: host, from and to are required", field)
+			}
+			if sink.Port < 1 || sink.Port > 65535 {
+				return fmt.Errorf("%s: port must be between 1 and 65535", field)
+			}
+		default:
+			return fmt.Errorf("%s: type must be %s, %s, %s, %s or %s", field, NotifySinkWebhook, NotifySinkDiscord, NotifySinkNtfy, NotifySinkGotify, NotifySinkSMTP)
+		}
+		switch {
+		case sink.Type == NotifySinkNtfy && sink.Topic == "":
+			return fmt.Errorf("%s: topic is required", field)
+		case sink.Type == NotifySinkGotify && sink.Token == "":
+			return fmt.Errorf("%s: token is required", field)
+		}
+	}
+	return nil
+}
+
+// ActivitySources expands activity_source (a comma-separated list, where
+// "both" means tautulli and plex) into the individual history sources to
+// fetch, or nil when a value is not recognised.
+func (c Config) ActivitySources() []string {
+	if strings.TrimSpace(c.ActivitySource) == "" {
+		return []string{ActivitySourceTautulli}
+	}
+	var out []string
+	seen := map[string]bool{}
+	add := func(source string) {
+		if !seen[source] {
+			seen[source] = true
+			out = append(out, source)
+		}
+	}
+	for _, part := range strings.Split(c.ActivitySource, ",") {
+		switch source := strings.ToLower(strings.TrimSpace(part)); source {
+		case ActivitySourceTautulli, ActivitySourcePlex, ActivitySourceJellyfin, ActivitySourceEmby:
+			add(source)
+		case ActivitySourceBoth:
+			add(ActivitySourceTautulli)
+			add(ActivitySourcePlex)
+		default:
+			return nil
+		}
+	}
+	return out
+}
+
+func validatePathMappings(field string, mappings []PathMapping) error {
+	for idx, mapping := range mappings {
+		if !strings.HasPrefix(mapping.From, "/") || !strings.HasPrefix(mapping.To, "/") {
+			return fmt.Errorf("%s[%d]: from and to must be absolute paths", field, idx)
+		}
+	}
+	return nil
+}
+
+func validateTorrent(t Torrent) error {
+	if t.Client == "" {
+		return nil
+	}
+	switch strings.ToLower(t.Client) {
+	case TorrentClientQBittorrent, TorrentClientTransmission, TorrentClientDeluge:
+	default:
+		return fmt.Errorf("torrent: client must be %s, %s or %s", TorrentClientQBittorrent, TorrentClientTransmission, TorrentClientDeluge)
+	}
+	if t.BaseURL == "" {
+		return fmt.Errorf("torrent: base_url is required")
+	}
+	if _, err := url.ParseRequestURI(t.BaseURL); err != nil {
+		return fmt.Errorf("torrent: base_url is invalid: %w", err)
+	}
+	if t.MinRatio < 0 || t.MinSeedDays < 0 {
+		return fmt.Errorf("torrent: min_ratio and min_seed_days must be non-negative")
+	}
+	return nil
+}
+
+func validateCustomRules(rules []CustomRule) error {
+	seen := map[string]bool{}
+	for idx, rule := range rules {
+		name := strings.TrimSpace(rule.Name)
+		if name == "" {
+			return fmt.Errorf("rules: custom[%d]: name is required", idx)
+		}
+		if seen[name] {
+			return fmt.Errorf("rules: custom rule %q is defined more than once", name)
+		}
+		seen[name] = true
+		switch rule.Effect {
+		case "", RuleEffectFlag, RuleEffectExclude:
+		default:
+			return fmt.Errorf("rules: custom rule %q: effect must be %s or %s", name, RuleEffectFlag, RuleEffectExclude)
+		}
+		if _, err := expr.Parse(rule.When); err != nil {
+			return fmt.Errorf("rules: custom rule %q: %w", name, err)
+		}
+	}
 	return nil
 }
 
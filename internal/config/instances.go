@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Instance is one named Radarr or Sonarr server. Its PathMappings apply only
+// to this instance, alongside the service-wide path_mappings.
+type Instance struct {
+	Name         string `yaml:"name"`
+	Service      `yaml:",inline"`
+	PathMappings []PathMapping `yaml:"path_mappings,omitempty"`
+}
+
+// Instances is the list of servers configured for one service. It accepts
+// either a single mapping, as in configs written before instances were named,
+// or a list of named instances.
+type Instances []Instance
+
+func (l *Instances) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var single Instance
+		if err := node.Decode(&single); err != nil {
+			return err
+		}
+		*l = Instances{single}
+		return nil
+	}
+	var list []Instance
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// MarshalYAML writes a lone instance back as a single mapping so saving a
+// config does not rewrite its layout.
+func (l Instances) MarshalYAML() (any, error) {
+	if len(l) == 1 {
+		return l[0], nil
+	}
+	return []Instance(l), nil
+}
+
+// Find returns the named instance. An empty name selects the first instance,
+// which is where items from reports without an instance name came from.
+func (l Instances) Find(name string) (Instance, bool) {
+	if name == "" {
+		if len(l) == 0 {
+			return Instance{}, false
+		}
+		return l[0], true
+	}
+	for _, inst := range l {
+		if inst.Name == name {
+			return inst, true
+		}
+	}
+	return Instance{}, false
+}
+
+// First returns the name of the first instance, or "" when none is configured.
+func (l Instances) First() string {
+	if len(l) == 0 {
+		return ""
+	}
+	return l[0].Name
+}
+
+func applyInstanceDefaults(service string, list Instances) {
+	if len(list) > 0 && strings.TrimSpace(list[0].Name) == "" {
+		list[0].Name = service
+	}
+}
+
+func validateInstances(service string, list Instances, seen map[string]string) error {
+	if len(list) == 0 {
+		return fmt.Errorf("%s: at least one instance is required", service)
+	}
+	for idx, inst := range list {
+		name := strings.TrimSpace(inst.Name)
+		if name == "" {
+			return fmt.Errorf("%s[%d]: name is required when more than one instance is configured", service, idx)
+		}
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("%s: instance name %q is already used by %s", service, name, other)
+		}
+		seen[name] = service
+		label := service
+		if len(list) > 1 {
+			label = fmt.Sprintf("%s[%s]", service, name)
+		}
+		if err := validateService(label, inst.Service); err != nil {
+			return err
+		}
+		if err := validatePathMappings(label+".path_mappings", inst.PathMappings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
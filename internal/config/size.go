@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1e3,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1e6,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1e9,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1e12,
+	"tib": 1 << 40,
+	"p":   1 << 50,
+	"pb":  1e15,
+	"pib": 1 << 50,
+}
+
+func ParseSize(value string) (int64, error) {
+	trimmed := strings.ToLower(strings.TrimSpace(value))
+	if trimmed == "" {
+		return 0, nil
+	}
+	idx := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := trimmed, ""
+	if idx >= 0 {
+		number, unit = trimmed[:idx], strings.TrimSpace(trimmed[idx:])
+	}
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", value, unit)
+	}
+	parsed, err := strconv.ParseFloat(number, 64)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(parsed * multiplier), nil
+}
+
+func ParsePercent(value string) (float64, error) {
+	trimmed := strings.TrimSuffix(strings.TrimSpace(value), "%")
+	if trimmed == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseFloat(strings.TrimSpace(trimmed), 64)
+	if err != nil || parsed < 0 || parsed > 100 {
+		return 0, fmt.Errorf("invalid percentage %q", value)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,230 @@
+// Package daemon runs scans on a schedule for the serve command, keeps their
+// reports in the state directory and optionally applies items that stayed
+// flagged through a grace period.
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-unraid-clean/internal/apply"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/leavingsoon"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/notify"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/scan"
+	"go-unraid-clean/internal/schedule"
+	"go-unraid-clean/internal/state"
+)
+
+const (
+	// ScanPrefix and ApplyPrefix name the reports the daemon saves.
+	ScanPrefix  = "scan"
+	ApplyPrefix = "apply"
+)
+
+type Options struct {
+	ConfigPath string
+	// Schedule overrides serve.schedule from the config.
+	Schedule string
+	// RunNow scans once at startup before waiting for the schedule.
+	RunNow bool
+}
+
+// Run scans on the schedule until ctx is cancelled. A scan or apply in
+// progress stops at the next item boundary; the config is reloaded before
+// every scan so exceptions added in between take effect.
+func Run(ctx context.Context, opts Options) error {
+	log := logging.L()
+	cfg, err := config.Load(opts.ConfigPath)
+	if err != nil {
+		return err
+	}
+	spec := cfg.Serve.Schedule
+	if opts.Schedule != "" {
+		spec = opts.Schedule
+	}
+	sched, err := schedule.Parse(spec)
+	if err != nil {
+		return err
+	}
+	log.Info().Str("schedule", spec).Str("state_dir", state.FromConfig(cfg).Root()).Bool("auto_apply", cfg.Serve.AutoApply.Enabled).Msg("Daemon started")
+
+	if opts.RunNow {
+		if done := runCycle(ctx, opts.ConfigPath); done {
+			return nil
+		}
+	}
+	for {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("schedule %q never matches", spec)
+		}
+		log.Info().Time("next_scan", next).Msg("Waiting for next scan")
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Info().Msg("Shutting down")
+			return nil
+		case <-timer.C:
+		}
+		if done := runCycle(ctx, opts.ConfigPath); done {
+			return nil
+		}
+	}
+}
+
+// runCycle runs one cycle, logging failures so the daemon keeps going. It
+// returns true when ctx was cancelled.
+func runCycle(ctx context.Context, cfgPath string) bool {
+	err := Cycle(ctx, cfgPath)
+	if ctx.Err() != nil {
+		logging.L().Info().Msg("Interrupted; shutting down")
+		return true
+	}
+	if err != nil {
+		logging.L().Error().Err(err).Msg("Scheduled scan failed")
+	}
+	return false
+}
+
+// Cycle runs one scheduled scan: it saves the report, prunes old reports,
+// updates how many consecutive scans flagged each item and, when auto-apply
+// is enabled, applies the items past the grace period.
+func Cycle(ctx context.Context, cfgPath string) error {
+	log := logging.L()
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return err
+	}
+	dir := state.FromConfig(cfg)
+
+	_, rep, err := Scan(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	streaks, err := LoadStreaks(dir)
+	if err != nil {
+		return err
+	}
+	streaks.update(rep)
+	if cfg.LeavingSoon.Enabled {
+		rescued, err := leavingsoon.Sync(ctx, cfg, dir, rep, streaks.flagged)
+		if err != nil {
+			log.Error().Err(err).Msg("Leaving Soon sync failed")
+		}
+		for _, key := range rescued {
+			streaks.reset(key)
+		}
+	}
+	if err := streaks.save(dir); err != nil {
+		return err
+	}
+	if err := prune(dir, ApplyPrefix, cfg.Serve.KeepReports); err != nil {
+		return err
+	}
+
+	if !cfg.Serve.AutoApply.Enabled {
+		return nil
+	}
+	due := dueItems(rep, streaks, cfg.Serve.AutoApply)
+	if len(due.Items) == 0 {
+		log.Info().Int("grace_scans", cfg.Serve.AutoApply.GraceScans).Msg("No items past the grace period")
+		return nil
+	}
+	applyPath, err := dir.SaveReport(ApplyPrefix, due)
+	if err != nil {
+		return err
+	}
+	log.Info().Int("count", len(due.Items)).Str("path", applyPath).Msg("Applying items past the grace period")
+	outcome, err := apply.Run(ctx, cfgPath, cfg, due, apply.Options{
+		Command:      "serve",
+		ReportPath:   applyPath,
+		ProgressPath: apply.ProgressPath(applyPath),
+		Revalidate:   true,
+	})
+	log.Info().Int("done", outcome.Done).Int("failed", outcome.Failed).Int("skipped", outcome.Skipped).Msg("Auto-apply finished")
+	results := notify.Results{Done: outcome.Done, Failed: outcome.Failed, Skipped: outcome.Skipped, Kept: outcome.Kept}
+	notify.Send(context.WithoutCancel(ctx), cfg.Notify, notify.ApplySummary(config.NotifyEventApply, outcome.Applied, applyPath, cfg.Notify.Top, results, err))
+	return err
+}
+
+// Scan runs a scan with the config's reclaim defaults, saves the report to
+// the state directory, prunes old ones and sends the scan notification.
+// Unlike Cycle it does not count towards the auto-apply grace period.
+func Scan(ctx context.Context, cfg config.Config) (string, *report.Report, error) {
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	rep, err := scan.Run(ctx, cfg, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	dir := state.FromConfig(cfg)
+	path, err := dir.SaveReport(ScanPrefix, rep)
+	if err != nil {
+		return "", nil, err
+	}
+	summary := report.Summarize(rep)
+	logging.L().Info().Str("path", path).Int("items", summary.Total).Int64("reclaimable_bytes", summary.ReclaimableBytes).Msg("Saved scan report")
+	if err := prune(dir, ScanPrefix, cfg.Serve.KeepReports); err != nil {
+		return "", nil, err
+	}
+	notify.Send(ctx, cfg.Notify, notify.ScanSummary(rep, path, cfg.Notify.Top))
+	return path, rep, nil
+}
+
+func prune(dir state.Dir, prefix string, keep int) error {
+	removed, err := dir.PruneReports(prefix, keep)
+	if err != nil {
+		return err
+	}
+	if len(removed) > 0 {
+		logging.L().Debug().Strs("removed", removed).Msg("Pruned old reports")
+	}
+	return nil
+}
+
+// dueItems returns a report of the items flagged in more than GraceScans
+// consecutive scans, with the auto-apply action in place of the planned one
+// when configured.
+func dueItems(rep *report.Report, streaks *Streaks, cfg config.AutoApply) *report.Report {
+	due := &report.Report{GeneratedAt: rep.GeneratedAt, Items: []report.Item{}}
+	for _, item := range rep.Items {
+		if streaks.Scans(item) <= cfg.GraceScans {
+			continue
+		}
+		switch item.Action {
+		case report.ActionKeep, report.ActionIgnore:
+			continue
+		}
+		if cfg.Action != "" {
+			item.Action = cfg.Action
+		}
+		due.Items = append(due.Items, item)
+	}
+	return due
+}
+
+// scanOptions mirrors the scan command's defaults from the config rules.
+func scanOptions(cfg config.Config) (scan.Options, error) {
+	reclaimBytes, err := config.ParseSize(cfg.Rules.ReclaimTarget)
+	if err != nil {
+		return scan.Options{}, fmt.Errorf("rules: reclaim_target: %w", err)
+	}
+	untilFree, err := config.ParsePercent(cfg.Rules.ReclaimUntilFree)
+	if err != nil {
+		return scan.Options{}, fmt.Errorf("rules: reclaim_until_free: %w", err)
+	}
+	return scan.Options{
+		SortBy:           "size",
+		SortOrder:        "desc",
+		ReclaimBytes:     reclaimBytes,
+		UntilFreePercent: untilFree,
+		FreeSpacePath:    cfg.Rules.FreeSpacePath,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"time"
+
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/state"
+)
+
+const streaksFile = "streaks.json"
+
+// Streaks records how many consecutive scheduled scans flagged each item, keyed
+// by report.Item.Key. Items drop out as soon as a scan no longer flags them.
+type Streaks struct {
+	LastScan time.Time         `json:"last_scan"`
+	Items    map[string]Streak `json:"items"`
+}
+
+type Streak struct {
+	Title     string    `json:"title"`
+	Scans     int       `json:"scans"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+func LoadStreaks(dir state.Dir) (*Streaks, error) {
+	streaks := &Streaks{Items: map[string]Streak{}}
+	if _, err := dir.ReadJSON(streaksFile, streaks); err != nil {
+		return nil, err
+	}
+	if streaks.Items == nil {
+		streaks.Items = map[string]Streak{}
+	}
+	return streaks, nil
+}
+
+func (s *Streaks) save(dir state.Dir) error {
+	return dir.WriteJSON(streaksFile, s)
+}
+
+// update counts a scan. Items the scan plans to keep, such as those marked
+// in progress, do not count as flagged.
+func (s *Streaks) update(rep *report.Report) {
+	next := make(map[string]Streak, len(rep.Items))
+	for _, item := range rep.Items {
+		if item.Action == report.ActionKeep {
+			continue
+		}
+		key := item.Key()
+		streak, ok := s.Items[key]
+		if !ok {
+			streak = Streak{FirstSeen: rep.GeneratedAt}
+		}
+		streak.Title = item.Title
+		streak.Scans++
+		streak.LastSeen = rep.GeneratedAt
+		next[key] = streak
+	}
+	s.Items = next
+	s.LastScan = rep.GeneratedAt
+}
+
+// Scans returns how many consecutive scans have flagged an item.
+func (s *Streaks) Scans(item report.Item) int {
+	return s.Items[item.Key()].Scans
+}
+
+func (s *Streaks) flagged(item report.Item) (int, time.Time) {
+	streak := s.Items[item.Key()]
+	return streak.Scans, streak.FirstSeen
+}
+
+// reset starts an item's streak over, e.g. after it was watched while leaving
+// soon.
+func (s *Streaks) reset(key string) {
+	delete(s.Items, key)
+}
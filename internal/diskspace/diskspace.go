@@ -0,0 +1,21 @@
+package diskspace
+
+type Stats struct {
+	TotalBytes int64
+	FreeBytes  int64
+}
+
+func (s Stats) FreePercent() float64 {
+	if s.TotalBytes <= 0 {
+		return 0
+	}
+	return float64(s.FreeBytes) / float64(s.TotalBytes) * 100
+}
+
+func (s Stats) TargetForFreePercent(percent float64) int64 {
+	want := int64(float64(s.TotalBytes) * percent / 100)
+	if want <= s.FreeBytes {
+		return 0
+	}
+	return want - s.FreeBytes
+}
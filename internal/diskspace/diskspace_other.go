@@ -0,0 +1,9 @@
+//go:build !unix
+
+package diskspace
+
+import "fmt"
+
+func Usage(path string) (Stats, error) {
+	return Stats{}, fmt.Errorf("free space lookup is not supported on this platform")
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package diskspace
+
+import (
+	"fmt"
+	"syscall"
+)
+
+func Usage(path string) (Stats, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return Stats{}, fmt.Errorf("statfs %s: %w", path, err)
+	}
+	return Stats{
+		TotalBytes: int64(fs.Blocks) * int64(fs.Bsize),
+		FreeBytes:  int64(fs.Bavail) * int64(fs.Bsize),
+	}, nil
+}
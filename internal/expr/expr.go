@@ -0,0 +1,400 @@
+// Package expr implements the small boolean expression language used by
+// custom cleanup rules. Expressions compare item fields with literals, e.g.
+//
+//	type == 'movie' && size_gib > 40 && inactivity_days > 60
+//
+// Values are numbers, strings, booleans or string lists. String equality is
+// case-insensitive; =~ and !~ match a regular expression; `in` tests list
+// membership or substring containment.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Program struct {
+	source string
+	root   node
+
+	mu      sync.Mutex
+	regexps map[string]*regexp.Regexp
+}
+
+// Parse checks the syntax of src and returns a program that can be evaluated.
+func Parse(src string) (*Program, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+	root, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{source: src, root: root, regexps: map[string]*regexp.Regexp{}}, nil
+}
+
+// Compile parses src and verifies every identifier is one of fields.
+func Compile(src string, fields []string) (*Program, error) {
+	prog, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := prog.Check(fields); err != nil {
+		return nil, err
+	}
+	return prog, nil
+}
+
+func (p *Program) String() string {
+	return p.source
+}
+
+// Check reports unknown identifiers and functions.
+func (p *Program) Check(fields []string) error {
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field] = true
+	}
+	var unknown []string
+	var walk func(n node) error
+	walk = func(n node) error {
+		switch v := n.(type) {
+		case ident:
+			if !known[v.name] {
+				unknown = append(unknown, v.name)
+			}
+		case unary:
+			return walk(v.operand)
+		case binary:
+			if err := walk(v.left); err != nil {
+				return err
+			}
+			return walk(v.right)
+		case list:
+			for _, item := range v.items {
+				if err := walk(item); err != nil {
+					return err
+				}
+			}
+		case call:
+			fn, ok := functions[v.name]
+			if !ok {
+				return fmt.Errorf("unknown function %q", v.name)
+			}
+			if len(v.args) != fn.arity {
+				return fmt.Errorf("%s expects %d arguments, got %d", v.name, fn.arity, len(v.args))
+			}
+			for _, arg := range v.args {
+				if err := walk(arg); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(p.root); err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		sorted := append([]string(nil), fields...)
+		sort.Strings(sorted)
+		return fmt.Errorf("unknown field %q (available: %s)", unknown[0], strings.Join(sorted, ", "))
+	}
+	return nil
+}
+
+// Match evaluates the program against env and requires a boolean result.
+func (p *Program) Match(env map[string]any) (bool, error) {
+	value, err := p.eval(p.root, env)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q does not evaluate to a boolean", p.source)
+	}
+	return result, nil
+}
+
+func (p *Program) eval(n node, env map[string]any) (any, error) {
+	switch v := n.(type) {
+	case literal:
+		return v.value, nil
+	case ident:
+		value, ok := env[v.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", v.name)
+		}
+		return normalize(value), nil
+	case list:
+		out := make([]string, 0, len(v.items))
+		for _, item := range v.items {
+			value, err := p.eval(item, env)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, fmt.Sprint(value))
+		}
+		return out, nil
+	case call:
+		fn, ok := functions[v.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %q", v.name)
+		}
+		args := make([]any, 0, len(v.args))
+		for _, arg := range v.args {
+			value, err := p.eval(arg, env)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, value)
+		}
+		return fn.call(args)
+	case unary:
+		operand, err := p.eval(v.operand, env)
+		if err != nil {
+			return nil, err
+		}
+		switch v.op {
+		case "!":
+			b, ok := operand.(bool)
+			if !ok {
+				return nil, fmt.Errorf("! expects a boolean, got %s", typeName(operand))
+			}
+			return !b, nil
+		case "-":
+			f, ok := operand.(float64)
+			if !ok {
+				return nil, fmt.Errorf("- expects a number, got %s", typeName(operand))
+			}
+			return -f, nil
+		}
+	case binary:
+		return p.evalBinary(v, env)
+	}
+	return nil, fmt.Errorf("invalid expression")
+}
+
+func (p *Program) evalBinary(v binary, env map[string]any) (any, error) {
+	left, err := p.eval(v.left, env)
+	if err != nil {
+		return nil, err
+	}
+	if v.op == "&&" || v.op == "||" {
+		lb, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s expects booleans, got %s", v.op, typeName(left))
+		}
+		if v.op == "&&" && !lb {
+			return false, nil
+		}
+		if v.op == "||" && lb {
+			return true, nil
+		}
+		right, err := p.eval(v.right, env)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s expects booleans, got %s", v.op, typeName(right))
+		}
+		return rb, nil
+	}
+
+	right, err := p.eval(v.right, env)
+	if err != nil {
+		return nil, err
+	}
+	switch v.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(v.op, left, right)
+	case "+", "-", "*", "/", "%":
+		return arithmetic(v.op, left, right)
+	case "=~", "!~":
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		if !lok || !rok {
+			return nil, fmt.Errorf("%s expects strings, got %s and %s", v.op, typeName(left), typeName(right))
+		}
+		re, err := p.regexp(rs)
+		if err != nil {
+			return nil, err
+		}
+		matched := re.MatchString(ls)
+		if v.op == "!~" {
+			return !matched, nil
+		}
+		return matched, nil
+	case "in":
+		return contains(right, left)
+	}
+	return nil, fmt.Errorf("unsupported operator %q", v.op)
+}
+
+func (p *Program) regexp(pattern string) (*regexp.Regexp, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if re, ok := p.regexps[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	p.regexps[pattern] = re
+	return re, nil
+}
+
+func normalize(value any) any {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []string:
+		return v
+	case nil:
+		return ""
+	default:
+		return v
+	}
+}
+
+func equal(left, right any) bool {
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		return ok && l == r
+	case string:
+		r, ok := right.(string)
+		return ok && strings.EqualFold(l, r)
+	case bool:
+		r, ok := right.(bool)
+		return ok && l == r
+	}
+	return false
+}
+
+func compare(op string, left, right any) (bool, error) {
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false, fmt.Errorf("cannot compare number with %s", typeName(right))
+		}
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot compare string with %s", typeName(right))
+		}
+		cmp = strings.Compare(strings.ToLower(l), strings.ToLower(r))
+	default:
+		return false, fmt.Errorf("cannot order %s values", typeName(left))
+	}
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func arithmetic(op string, left, right any) (any, error) {
+	if op == "+" {
+		if ls, ok := left.(string); ok {
+			if rs, ok := right.(string); ok {
+				return ls + rs, nil
+			}
+		}
+	}
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s expects numbers, got %s and %s", op, typeName(left), typeName(right))
+	}
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+func contains(container, needle any) (bool, error) {
+	switch c := container.(type) {
+	case []string:
+		n := fmt.Sprint(needle)
+		if f, ok := needle.(float64); ok {
+			n = formatNumber(f)
+		}
+		for _, item := range c {
+			if strings.EqualFold(item, n) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case string:
+		n, ok := needle.(string)
+		if !ok {
+			return false, fmt.Errorf("in expects a string on the left of a string, got %s", typeName(needle))
+		}
+		return strings.Contains(strings.ToLower(c), strings.ToLower(n)), nil
+	}
+	return false, fmt.Errorf("in expects a list or string on the right, got %s", typeName(container))
+}
+
+func formatNumber(f float64) string {
+	if f == math.Trunc(f) {
+		return fmt.Sprintf("%.0f", f)
+	}
+	return fmt.Sprint(f)
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []string:
+		return "list"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
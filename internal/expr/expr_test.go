@@ -0,0 +1,224 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+var env = map[string]any{
+	"type":            "movie",
+	"title":           "The Matrix (1999)",
+	"size_gib":        42,
+	"inactivity_days": int64(90),
+	"rating":          float32(7.5),
+	"watched":         false,
+	"instance":        "radarr",
+	"also_in":         []string{"radarr-4k", "radarr-anime"},
+	"genres":          []string{"Action", "Science Fiction"},
+	"years":           []string{"1999", "2003"},
+	"studio":          nil,
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		// Precedence: * before +, + before comparison, comparison before
+		// !, ! before &&, && before ||.
+		{"1 + 2 * 3 == 7", true},
+		{"(1 + 2) * 3 == 9", true},
+		{"10 - 4 - 3 == 3", true},
+		{"12 / 2 / 3 == 2", true},
+		{"10 % 4 == 2", true},
+		{"-2 * -3 == 6", true},
+		{"size_gib > 40 + 1", true},
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"!watched && size_gib > 40", true},
+		{"!(size_gib > 40)", false},
+		{"!true || true", true},
+		{"false && unknown_field", false},
+		{"true || unknown_field", true},
+
+		// Keywords are case-insensitive aliases.
+		{"type == 'movie' and size_gib > 40", true},
+		{"type == 'series' or inactivity_days >= 90", true},
+		{"not watched", true},
+		{"NOT watched AND type == 'movie'", true},
+		{"not type == 'series'", true},
+
+		// Numbers, strings and booleans.
+		{"inactivity_days == 90", true},
+		{"rating >= 7.5 && rating < 8", true},
+		{"1_000 == 1000", true},
+		{".5 == 0.5", true},
+		{"type == 'MOVIE'", true},
+		{`type != "series"`, true},
+		{"title > 'a'", true},
+		{"'a' + 'b' == 'ab'", true},
+		{`'it\'s' == "it's"`, true},
+		{"watched == false", true},
+		{"studio == ''", true},
+		{"type == 1", false},
+
+		// in on lists and strings.
+		{"'radarr-4k' in also_in", true},
+		{"'RADARR-ANIME' in also_in", true},
+		{"'sonarr' in also_in", false},
+		{"instance in ['radarr', 'sonarr']", true},
+		{"1999 in years", true},
+		{"2001 in years", false},
+		{"type in []", false},
+		{"'matrix' in title", true},
+		{"'reloaded' in title", false},
+		{"not 'matrix' in title", false},
+
+		// Regular expressions are case-insensitive.
+		{"title =~ '^the matrix'", true},
+		// Backslashes escape the next character inside string literals, so
+		// regexp escapes are doubled.
+		{`title =~ '\\(\\d{4}\\)$'`, true},
+		{"title !~ 'reloaded'", true},
+		{"title !~ 'MATRIX'", false},
+
+		// Functions.
+		{"lower(type) == 'movie'", true},
+		{"startswith(title, 'the')", true},
+		{"endswith(title, '(1999)')", true},
+		{"contains(genres, 'science fiction')", true},
+		{"len(also_in) == 2 && len(type) == 5", true},
+		{"LEN(also_in) > 1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog, err := Parse(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := prog.Match(env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"size_gib > 'big'", "cannot compare number with string"},
+		{"type < 3", "cannot compare string with number"},
+		{"watched < true", "cannot order boolean values"},
+		{"size_gib + 'x' > 1", "+ expects numbers, got number and string"},
+		{"type * 2 == 1", "* expects numbers, got string and number"},
+		{"-type == 1", "- expects a number, got string"},
+		{"!size_gib", "! expects a boolean, got number"},
+		{"size_gib && true", "&& expects booleans, got number"},
+		{"false || type", "|| expects booleans, got string"},
+		{"size_gib =~ '4'", "=~ expects strings, got number and string"},
+		{"title =~ '('", `invalid pattern "("`},
+		{"'x' in size_gib", "in expects a list or string on the right, got number"},
+		{"1 in title", "in expects a string on the left of a string, got number"},
+		{"lower(size_gib) == ''", "lower expects a string, got number"},
+		{"len(watched) == 0", "len expects a string or list, got boolean"},
+		{"size_gib / 0 > 1", "division by zero"},
+		{"size_gib % (2 - 2) > 1", "division by zero"},
+		{"missing == 1", `unknown field "missing"`},
+		{"upper(type) == 'MOVIE'", `unknown function "upper"`},
+		{"size_gib + 1", "does not evaluate to a boolean"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog, err := Parse(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = prog.Match(env)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Match() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"", "empty expression"},
+		{"   ", "empty expression"},
+		{"title == 'matrix", "unterminated string at 9"},
+		{`title == "matrix\"`, "unterminated string at 9"},
+		{"(size_gib > 40", "expected ) at 14"},
+		{"((true)", "expected ) at 7"},
+		{"size_gib > 40)", `unexpected ")" at 13`},
+		{"()", `unexpected ")" at 1`},
+		{"lower(type", `unexpected "" at 10`},
+		{"type in ['a', 'b'", `unexpected "" at 17`},
+		{"type == ", "unexpected end of expression"},
+		{"size_gib > > 1", `unexpected ">" at 11`},
+		{"size_gib # 1", `unexpected character '#' at 9`},
+		{"1.2.3 > 0", `invalid number "1.2.3" at 0`},
+		{"a == b == c", `unexpected "==" at 7`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	fields := []string{"type", "size_gib", "also_in"}
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"type == 'movie' && size_gib > 40 && 'x' in also_in", ""},
+		{"type == 'movie' && sise_gib > 40", `unknown field "sise_gib" (available: also_in, size_gib, type)`},
+		{"lower(titel) == 'x'", `unknown field "titel"`},
+		{"upper(type) == 'MOVIE'", `unknown function "upper"`},
+		{"lower(type, 'x') == 'movie'", "lower expects 1 arguments, got 2"},
+		{"startswith(type)", "startswith expects 2 arguments, got 1"},
+		{"type == 'movie' &&", "unexpected end of expression"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Compile(tt.src, fields)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Compile() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compile() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgramCachesRegexps(t *testing.T) {
+	prog, err := Parse("title =~ 'matrix' && title !~ 'matrix reloaded'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if ok, err := prog.Match(env); err != nil || !ok {
+			t.Fatalf("Match() = %v, %v", ok, err)
+		}
+	}
+	if len(prog.regexps) != 2 {
+		t.Errorf("cached %d patterns, want 2", len(prog.regexps))
+	}
+}
@@ -0,0 +1,63 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+type function struct {
+	arity int
+	call  func(args []any) (any, error)
+}
+
+var functions = map[string]function{
+	"lower": {arity: 1, call: func(args []any) (any, error) {
+		s, err := stringArg("lower", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return strings.ToLower(s), nil
+	}},
+	"contains": {arity: 2, call: func(args []any) (any, error) {
+		return contains(args[0], args[1])
+	}},
+	"startswith": {arity: 2, call: func(args []any) (any, error) {
+		s, err := stringArg("startswith", args[0])
+		if err != nil {
+			return nil, err
+		}
+		prefix, err := stringArg("startswith", args[1])
+		if err != nil {
+			return nil, err
+		}
+		return strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix)), nil
+	}},
+	"endswith": {arity: 2, call: func(args []any) (any, error) {
+		s, err := stringArg("endswith", args[0])
+		if err != nil {
+			return nil, err
+		}
+		suffix, err := stringArg("endswith", args[1])
+		if err != nil {
+			return nil, err
+		}
+		return strings.HasSuffix(strings.ToLower(s), strings.ToLower(suffix)), nil
+	}},
+	"len": {arity: 1, call: func(args []any) (any, error) {
+		switch v := args[0].(type) {
+		case string:
+			return float64(len(v)), nil
+		case []string:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("len expects a string or list, got %s", typeName(args[0]))
+	}},
+}
+
+func stringArg(name string, value any) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s expects a string, got %s", name, typeName(value))
+	}
+	return s, nil
+}
@@ -0,0 +1,97 @@
+// Package fsinspect measures how much space deleting a set of files would
+// actually free, taking hardlinks into account.
+package fsinspect
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// Usage summarizes the regular files found under a path. Each inode is
+// counted once. LinkedBytes belong to files that still have links outside
+// the inspected set, so deleting the set does not free them.
+type Usage struct {
+	Files            int
+	TotalBytes       int64
+	ReclaimableBytes int64
+	LinkedBytes      int64
+}
+
+type inodeKey struct {
+	dev uint64
+	ino uint64
+}
+
+type inode struct {
+	size  int64
+	nlink uint64
+	seen  uint64
+}
+
+type collector struct {
+	usage  Usage
+	inodes map[inodeKey]*inode
+}
+
+func newCollector() *collector {
+	return &collector{inodes: map[inodeKey]*inode{}}
+}
+
+func (c *collector) add(info fs.FileInfo) {
+	if !info.Mode().IsRegular() {
+		return
+	}
+	c.usage.Files++
+	key, nlink, ok := fileIdentity(info)
+	if !ok {
+		c.usage.TotalBytes += info.Size()
+		c.usage.ReclaimableBytes += info.Size()
+		return
+	}
+	entry, exists := c.inodes[key]
+	if !exists {
+		entry = &inode{size: info.Size(), nlink: nlink}
+		c.inodes[key] = entry
+	}
+	entry.seen++
+}
+
+func (c *collector) result() Usage {
+	out := c.usage
+	for _, entry := range c.inodes {
+		out.TotalBytes += entry.size
+		if entry.seen >= entry.nlink {
+			out.ReclaimableBytes += entry.size
+		} else {
+			out.LinkedBytes += entry.size
+		}
+	}
+	return out
+}
+
+// Inspect walks each path (a file or directory) without following symlinks
+// and measures the regular files found, such as a movie folder or the
+// episode files of one season.
+func Inspect(paths ...string) (Usage, error) {
+	c := newCollector()
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			c.add(info)
+			return nil
+		})
+		if err != nil {
+			return Usage{}, err
+		}
+	}
+	return c.result(), nil
+}
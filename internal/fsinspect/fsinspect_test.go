@@ -0,0 +1,112 @@
+//go:build unix
+
+package fsinspect
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// library lays out a movie folder with a unique file, a file hardlinked into
+// a downloads folder outside it, and a file hardlinked twice inside it.
+func library(t *testing.T) (movie string, downloads string) {
+	t.Helper()
+	root := t.TempDir()
+	movie = filepath.Join(root, "movies", "Movie (2020)")
+	downloads = filepath.Join(root, "downloads")
+	for _, dir := range []string{filepath.Join(movie, "extras"), downloads} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(t, filepath.Join(movie, "movie.mkv"), 100)
+	write(t, filepath.Join(movie, "extras", "seeded.mkv"), 50)
+	link(t, filepath.Join(movie, "extras", "seeded.mkv"), filepath.Join(downloads, "seeded.mkv"))
+	write(t, filepath.Join(movie, "sample.mkv"), 30)
+	link(t, filepath.Join(movie, "sample.mkv"), filepath.Join(movie, "extras", "sample-copy.mkv"))
+	return movie, downloads
+}
+
+func write(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func link(t *testing.T, from, to string) {
+	t.Helper()
+	if err := os.Link(from, to); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	movie, downloads := library(t)
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  Usage
+	}{
+		{
+			name:  "folder with links outside",
+			paths: []string{movie},
+			want:  Usage{Files: 4, TotalBytes: 180, ReclaimableBytes: 130, LinkedBytes: 50},
+		},
+		{
+			name:  "all links inside the set",
+			paths: []string{movie, downloads},
+			want:  Usage{Files: 5, TotalBytes: 180, ReclaimableBytes: 180},
+		},
+		{
+			name:  "single unique file",
+			paths: []string{filepath.Join(movie, "movie.mkv")},
+			want:  Usage{Files: 1, TotalBytes: 100, ReclaimableBytes: 100},
+		},
+		{
+			name:  "single linked file",
+			paths: []string{filepath.Join(movie, "extras", "seeded.mkv")},
+			want:  Usage{Files: 1, TotalBytes: 50, LinkedBytes: 50},
+		},
+		{
+			name: "empty set",
+			want: Usage{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Inspect(tt.paths...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Inspect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspectMissingPath(t *testing.T) {
+	_, err := Inspect(filepath.Join(t.TempDir(), "missing"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Inspect() error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestInspectIgnoresSymlinks(t *testing.T) {
+	movie, downloads := library(t)
+	if err := os.Symlink(downloads, filepath.Join(movie, "downloads")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Inspect(movie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Files != 4 || got.TotalBytes != 180 {
+		t.Errorf("Inspect() = %+v, want the symlinked folder skipped", got)
+	}
+}
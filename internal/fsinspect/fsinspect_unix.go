@@ -0,0 +1,16 @@
+//go:build unix
+
+package fsinspect
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+func fileIdentity(info fs.FileInfo) (inodeKey, uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return inodeKey{}, 0, false
+	}
+	return inodeKey{dev: uint64(st.Dev), ino: uint64(st.Ino)}, uint64(st.Nlink), true
+}
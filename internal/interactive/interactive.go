@@ -8,21 +8,39 @@ import (
 	"strings"
 	"time"
 
-	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/apply"
 	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/journal"
 	"go-unraid-clean/internal/logging"
 	"go-unraid-clean/internal/report"
 )
 
-func Run(ctx context.Context, cfgPath string, cfg config.Config, rep *report.Report) error {
+// Outcome counts what the reviewer did. Failures lists every action that
+// failed, including ones that were retried or skipped afterwards.
+type Outcome struct {
+	Kept     int
+	Applied  []apply.Applied
+	Failures []string
+}
+
+func Run(ctx context.Context, cfgPath string, cfg config.Config, rep *report.Report, reportPath string) (Outcome, error) {
 	log := logging.L()
-	radarr, err := clients.NewRadarrClient(cfg.Radarr.BaseURL, cfg.Radarr.APIKey)
+	var outcome Outcome
+	executor, err := apply.NewExecutor(&cfg, journal.Origin{Command: "interactive", Report: reportPath})
 	if err != nil {
-		return err
+		return outcome, err
 	}
-	sonarr, err := clients.NewSonarrClient(cfg.Sonarr.BaseURL, cfg.Sonarr.APIKey)
-	if err != nil {
-		return err
+	execute := func(item report.Item, action string) (apply.Result, error) {
+		result, err := executor.Execute(ctx, item, action)
+		if err == nil {
+			item.Action = action
+			outcome.Applied = append(outcome.Applied, apply.Applied{Item: item, BytesFreed: result.BytesFreed})
+		}
+		return result, err
+	}
+	failed := func(item report.Item, what string, err error) {
+		fmt.Printf("%s failed: %s\n", what, err)
+		outcome.Failures = append(outcome.Failures, fmt.Sprintf("%s %q: %s: %s", item.Type, item.Title, strings.ToLower(what), err))
 	}
 
 	reader := bufio.NewReader(os.Stdin)
@@ -30,7 +48,30 @@ func Run(ctx context.Context, cfgPath string, cfg config.Config, rep *report.Rep
 
 	for idx, item := range rep.Items {
 		fmt.Printf("\n[%d/%d] %s (%s)\n", idx+1, len(rep.Items), item.Title, item.Type)
+		if item.Instance != "" {
+			fmt.Printf("  Instance: %s\n", item.Instance)
+		}
+		if len(item.AlsoIn) > 0 {
+			fmt.Printf("  Also in: %s\n", strings.Join(item.AlsoIn, ", "))
+		}
 		fmt.Printf("  Size: %s GiB\n", formatSizeGiB(item.SizeBytes))
+		if item.ReclaimableBytes != nil && *item.ReclaimableBytes != item.SizeBytes {
+			fmt.Printf("  Reclaimable: %s GiB\n", formatSizeGiB(*item.ReclaimableBytes))
+		}
+		if len(item.Disks) > 0 {
+			parts := make([]string, 0, len(item.Disks))
+			for _, d := range item.Disks {
+				parts = append(parts, fmt.Sprintf("%s %s GiB", d.Disk, formatSizeGiB(d.Bytes)))
+			}
+			fmt.Printf("  Disks: %s\n", strings.Join(parts, ", "))
+		}
+		for _, t := range item.Torrents {
+			goal := "below seeding goal"
+			if t.GoalMet {
+				goal = "seeding goal met"
+			}
+			fmt.Printf("  Torrent: %s (%s, ratio %.2f, %.1f days seeded, %s)\n", t.Name, t.State, t.Ratio, float64(t.SeedingSeconds)/86400, goal)
+		}
 		fmt.Printf("  Added: %s\n", formatOptionalTime(item.AddedAt))
 		fmt.Printf("  First activity: %s\n", formatOptionalTime(item.FirstActivityAt))
 		fmt.Printf("  Last activity: %s\n", formatOptionalTime(item.LastActivityAt))
@@ -44,22 +85,44 @@ func Run(ctx context.Context, cfgPath string, cfg config.Config, rep *report.Rep
 		if item.TotalWatchHours > 0 {
 			fmt.Printf("  Total watch hours: %.1f\n", item.TotalWatchHours)
 		}
-		if item.Type == "series" && item.SeriesStatus != "" {
+		if (item.Type == "series" || item.Type == "season") && item.SeriesStatus != "" {
 			fmt.Printf("  Status: %s\n", item.SeriesStatus)
 		}
 		fmt.Printf("  Reason: %s\n", item.Reason)
+		if item.RequestedBy != "" {
+			fmt.Printf("  Requested by: %s (%s)\n", item.RequestedBy, formatOptionalTime(item.RequestedAt))
+		}
+		if item.Protection != "" {
+			fmt.Printf("  Protection: %s\n", item.Protection)
+		}
+		if len(item.ScoreBreakdown) > 0 {
+			fmt.Printf("  Score: %.2f\n", item.Score)
+			for _, factor := range item.ScoreBreakdown {
+				fmt.Printf("    %-20s %+7.2f  (%.1f x %.3g)\n", factor.Factor, factor.Contribution, factor.Value, factor.Weight)
+			}
+		}
 		fmt.Printf("  Path: %s\n", item.Path)
+		if item.LocalPath != "" && item.LocalPath != item.Path {
+			fmt.Printf("  Local path: %s\n", item.LocalPath)
+		}
+		if item.Action != "" {
+			fmt.Printf("  Planned action: %s\n", item.Action)
+		}
 
-		options := "[s]kip [a]always-ignore [d]elete [f]delete-files [q]uit"
+		options := "[s]kip [a]always-ignore [d]elete [f]delete-files"
 		if item.Type == "series" {
-			options = "[s]kip [a]always-ignore [d]elete [f]delete-files [l]last-season [q]uit"
+			options += " [l]last-season"
+		}
+		if cfg.Quarantine.Path != "" {
+			options += " [m]quarantine"
 		}
+		options += " [q]uit"
 
 		for {
 			fmt.Printf("Action %s: ", options)
 			input, err := reader.ReadString('\n')
 			if err != nil {
-				return err
+				return outcome, err
 			}
 			choice := strings.ToLower(strings.TrimSpace(input))
 			if choice == "" {
@@ -68,50 +131,65 @@ func Run(ctx context.Context, cfgPath string, cfg config.Config, rep *report.Rep
 			switch choice {
 			case "s", "skip", "keep":
 				log.Debug().Str("title", item.Title).Msg("Keeping item")
+				outcome.Kept++
 				goto nextItem
 			case "a", "always", "always-ignore", "ignore", "safe", "whitelist", "exclude":
-				changes, err := addException(&cfg, item)
+				result, err := execute(item, report.ActionIgnore)
 				if err != nil {
-					fmt.Printf("Failed to add exception: %s\n", err)
+					failed(item, "Add exception", err)
 					continue
 				}
 				changedConfig = true
-				if len(changes) > 0 {
-					fmt.Printf("Added to exceptions: %s\n", strings.Join(changes, ", "))
+				if len(result.Exceptions) > 0 {
+					fmt.Printf("Added to exceptions: %s\n", strings.Join(result.Exceptions, ", "))
 				} else {
 					fmt.Println("Added to exceptions.")
 				}
 				goto nextItem
 			case "d", "delete":
-				if err := deleteItem(ctx, radarr, sonarr, item); err != nil {
-					fmt.Printf("Delete failed: %s\n", err)
+				if _, err := execute(item, report.ActionDelete); err != nil {
+					failed(item, "Delete", err)
 					continue
 				}
 				goto nextItem
 			case "f", "files":
-				if err := deleteFilesOnly(ctx, radarr, sonarr, item); err != nil {
-					fmt.Printf("Delete files failed: %s\n", err)
+				if _, err := execute(item, report.ActionDeleteFiles); err != nil {
+					failed(item, "Delete files", err)
 					continue
 				}
 				goto nextItem
 			case "l", "last":
-				if item.Type != "series" {
-					fmt.Println("Last-season is only valid for series.")
+				if err := report.CheckAction(item, report.ActionKeepLastSeason); err != nil {
+					fmt.Printf("Cannot keep the last season: %s.\n", err)
 					continue
 				}
-				if err := keepLastSeason(ctx, sonarr, item); err != nil {
-					fmt.Printf("Keep last season failed: %s\n", err)
+				if _, err := execute(item, report.ActionKeepLastSeason); err != nil {
+					failed(item, "Keep last season", err)
+					continue
+				}
+				goto nextItem
+			case "m", "move", "quarantine":
+				if cfg.Quarantine.Path == "" {
+					fmt.Println("Quarantine is not configured.")
+					continue
+				}
+				if err := report.CheckAction(item, report.ActionQuarantine); err != nil {
+					fmt.Printf("Cannot quarantine: %s.\n", err)
+					continue
+				}
+				if _, err := execute(item, report.ActionQuarantine); err != nil {
+					failed(item, "Quarantine", err)
 					continue
 				}
 				goto nextItem
 			case "q", "quit":
 				if changedConfig {
 					if err := config.Save(cfgPath, cfg); err != nil {
-						return err
+						return outcome, err
 					}
 					fmt.Printf("Saved config to %s\n", cfgPath)
 				}
-				return nil
+				return outcome, nil
 			default:
 				fmt.Println("Unknown action.")
 			}
@@ -123,247 +201,11 @@ func Run(ctx context.Context, cfgPath string, cfg config.Config, rep *report.Rep
 
 	if changedConfig {
 		if err := config.Save(cfgPath, cfg); err != nil {
-			return err
+			return outcome, err
 		}
 		fmt.Printf("Saved config to %s\n", cfgPath)
 	}
-	return nil
-}
-
-func addException(cfg *config.Config, item report.Item) ([]string, error) {
-	var changes []string
-	switch item.Type {
-	case "movie":
-		if item.RadarrID != nil {
-			before := len(cfg.Exceptions.Movies.RadarrIDs)
-			cfg.Exceptions.Movies.RadarrIDs = config.AddUniqueInt(cfg.Exceptions.Movies.RadarrIDs, *item.RadarrID)
-			if len(cfg.Exceptions.Movies.RadarrIDs) > before {
-				changes = append(changes, fmt.Sprintf("radarr_id=%d", *item.RadarrID))
-			}
-		}
-		if item.TMDBID != nil {
-			before := len(cfg.Exceptions.Movies.TMDBIDs)
-			cfg.Exceptions.Movies.TMDBIDs = config.AddUniqueInt(cfg.Exceptions.Movies.TMDBIDs, *item.TMDBID)
-			if len(cfg.Exceptions.Movies.TMDBIDs) > before {
-				changes = append(changes, fmt.Sprintf("tmdb_id=%d", *item.TMDBID))
-			}
-		}
-		if item.IMDBID != "" {
-			before := len(cfg.Exceptions.Movies.IMDBIDs)
-			cfg.Exceptions.Movies.IMDBIDs = config.AddUniqueString(cfg.Exceptions.Movies.IMDBIDs, item.IMDBID)
-			if len(cfg.Exceptions.Movies.IMDBIDs) > before {
-				changes = append(changes, fmt.Sprintf("imdb_id=%s", item.IMDBID))
-			}
-		}
-		if item.Title != "" {
-			before := len(cfg.Exceptions.Movies.Titles)
-			cfg.Exceptions.Movies.Titles = config.AddUniqueString(cfg.Exceptions.Movies.Titles, item.Title)
-			if len(cfg.Exceptions.Movies.Titles) > before {
-				changes = append(changes, fmt.Sprintf("title=%q", item.Title))
-			}
-		}
-		if item.Path != "" {
-			before := len(cfg.Exceptions.Movies.PathPrefixes)
-			cfg.Exceptions.Movies.PathPrefixes = config.AddUniqueString(cfg.Exceptions.Movies.PathPrefixes, item.Path)
-			if len(cfg.Exceptions.Movies.PathPrefixes) > before {
-				changes = append(changes, fmt.Sprintf("path=%q", item.Path))
-			}
-		}
-		return changes, nil
-	case "series":
-		if item.SonarrID != nil {
-			before := len(cfg.Exceptions.Series.SonarrIDs)
-			cfg.Exceptions.Series.SonarrIDs = config.AddUniqueInt(cfg.Exceptions.Series.SonarrIDs, *item.SonarrID)
-			if len(cfg.Exceptions.Series.SonarrIDs) > before {
-				changes = append(changes, fmt.Sprintf("sonarr_id=%d", *item.SonarrID))
-			}
-		}
-		if item.TVDBID != nil {
-			before := len(cfg.Exceptions.Series.TVDBIDs)
-			cfg.Exceptions.Series.TVDBIDs = config.AddUniqueInt(cfg.Exceptions.Series.TVDBIDs, *item.TVDBID)
-			if len(cfg.Exceptions.Series.TVDBIDs) > before {
-				changes = append(changes, fmt.Sprintf("tvdb_id=%d", *item.TVDBID))
-			}
-		}
-		if item.IMDBID != "" {
-			before := len(cfg.Exceptions.Series.IMDBIDs)
-			cfg.Exceptions.Series.IMDBIDs = config.AddUniqueString(cfg.Exceptions.Series.IMDBIDs, item.IMDBID)
-			if len(cfg.Exceptions.Series.IMDBIDs) > before {
-				changes = append(changes, fmt.Sprintf("imdb_id=%s", item.IMDBID))
-			}
-		}
-		if item.Title != "" {
-			before := len(cfg.Exceptions.Series.Titles)
-			cfg.Exceptions.Series.Titles = config.AddUniqueString(cfg.Exceptions.Series.Titles, item.Title)
-			if len(cfg.Exceptions.Series.Titles) > before {
-				changes = append(changes, fmt.Sprintf("title=%q", item.Title))
-			}
-		}
-		if item.Path != "" {
-			before := len(cfg.Exceptions.Series.PathPrefixes)
-			cfg.Exceptions.Series.PathPrefixes = config.AddUniqueString(cfg.Exceptions.Series.PathPrefixes, item.Path)
-			if len(cfg.Exceptions.Series.PathPrefixes) > before {
-				changes = append(changes, fmt.Sprintf("path=%q", item.Path))
-			}
-		}
-		return changes, nil
-	default:
-		return nil, fmt.Errorf("unsupported item type: %s", item.Type)
-	}
-}
-
-func deleteItem(ctx context.Context, radarr *clients.RadarrClient, sonarr *clients.SonarrClient, item report.Item) error {
-	switch item.Type {
-	case "movie":
-		if item.RadarrID == nil {
-			return fmt.Errorf("missing radarr_id")
-		}
-		logging.L().Debug().Str("title", item.Title).Int("radarr_id", *item.RadarrID).Msg("Deleting movie")
-		return radarr.DeleteMovie(ctx, *item.RadarrID, true)
-	case "series":
-		if item.SonarrID == nil {
-			return fmt.Errorf("missing sonarr_id")
-		}
-		logging.L().Debug().Str("title", item.Title).Int("sonarr_id", *item.SonarrID).Msg("Deleting series")
-		if err := sonarr.DeleteSeries(ctx, *item.SonarrID, true); err != nil {
-			return err
-		}
-		if err := waitForSeriesRemoval(ctx, sonarr, *item.SonarrID); err != nil {
-			return err
-		}
-		return nil
-	default:
-		return fmt.Errorf("unsupported item type: %s", item.Type)
-	}
-}
-
-func deleteFilesOnly(ctx context.Context, radarr *clients.RadarrClient, sonarr *clients.SonarrClient, item report.Item) error {
-	switch item.Type {
-	case "movie":
-		if item.RadarrID == nil {
-			return fmt.Errorf("missing radarr_id")
-		}
-		logging.L().Debug().Str("title", item.Title).Int("radarr_id", *item.RadarrID).Msg("Deleting movie files only")
-		files, err := radarr.MovieFiles(ctx, *item.RadarrID)
-		if err != nil {
-			return fmt.Errorf("movie %s (%d): %w", item.Title, *item.RadarrID, err)
-		}
-		if len(files) == 0 {
-			return fmt.Errorf("no movie files found")
-		}
-		logging.L().Debug().Int("count", len(files)).Msg("Movie files to delete")
-		for _, file := range files {
-			if err := radarr.DeleteMovieFile(ctx, file.ID); err != nil {
-				return fmt.Errorf("movie %s (%d): %w", item.Title, *item.RadarrID, err)
-			}
-		}
-		return nil
-	case "series":
-		if item.SonarrID == nil {
-			return fmt.Errorf("missing sonarr_id")
-		}
-		logging.L().Debug().Str("title", item.Title).Int("sonarr_id", *item.SonarrID).Msg("Deleting all episode files")
-		ids, err := episodeFileIDs(ctx, sonarr, *item.SonarrID, 0)
-		if err != nil {
-			return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
-		}
-		if len(ids) == 0 {
-			return fmt.Errorf("no episode files found")
-		}
-		logging.L().Debug().Int("count", len(ids)).Msg("Episode files to delete")
-		for _, id := range ids {
-			if err := sonarr.DeleteEpisodeFile(ctx, id); err != nil {
-				return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
-			}
-		}
-		return nil
-	default:
-		return fmt.Errorf("unsupported item type: %s", item.Type)
-	}
-}
-
-func keepLastSeason(ctx context.Context, sonarr *clients.SonarrClient, item report.Item) error {
-	if item.SonarrID == nil {
-		return fmt.Errorf("missing sonarr_id")
-	}
-	logging.L().Debug().Str("title", item.Title).Int("sonarr_id", *item.SonarrID).Msg("Fetching series detail for last season")
-	series, err := sonarr.SeriesByID(ctx, *item.SonarrID)
-	if err != nil {
-		return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
-	}
-	lastSeason := 0
-	for _, season := range series.Seasons {
-		if season.SeasonNumber == 0 {
-			continue
-		}
-		if season.Statistics.EpisodeFileCount == 0 {
-			continue
-		}
-		if season.SeasonNumber > lastSeason {
-			lastSeason = season.SeasonNumber
-		}
-	}
-	if lastSeason == 0 {
-		return fmt.Errorf("no seasons with files found")
-	}
-	logging.L().Debug().Int("season", lastSeason).Msg("Keeping last season")
-	ids, err := episodeFileIDs(ctx, sonarr, *item.SonarrID, lastSeason)
-	if err != nil {
-		return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
-	}
-	if len(ids) == 0 {
-		return fmt.Errorf("no episode files found to delete")
-	}
-	logging.L().Debug().Int("count", len(ids)).Msg("Episode files to delete (keeping last season)")
-	for _, id := range ids {
-		if err := sonarr.DeleteEpisodeFile(ctx, id); err != nil {
-			return fmt.Errorf("series %s (%d): %w", item.Title, *item.SonarrID, err)
-		}
-	}
-	return nil
-}
-
-func episodeFileIDs(ctx context.Context, sonarr *clients.SonarrClient, seriesID int, keepSeason int) ([]int, error) {
-	logging.L().Debug().Int("sonarr_id", seriesID).Int("keep_season", keepSeason).Msg("Fetching episodes for series")
-	episodes, err := sonarr.Episodes(ctx, seriesID)
-	if err != nil {
-		return nil, err
-	}
-	ids := map[int]struct{}{}
-	for _, ep := range episodes {
-		if ep.EpisodeFileID == 0 {
-			continue
-		}
-		if ep.SeasonNumber == 0 {
-			continue
-		}
-		if keepSeason > 0 && ep.SeasonNumber >= keepSeason {
-			continue
-		}
-		ids[ep.EpisodeFileID] = struct{}{}
-	}
-	out := make([]int, 0, len(ids))
-	for id := range ids {
-		out = append(out, id)
-	}
-	return out, nil
-}
-
-func waitForSeriesRemoval(ctx context.Context, sonarr *clients.SonarrClient, seriesID int) error {
-	const attempts = 5
-	delay := 500 * time.Millisecond
-	for i := 0; i < attempts; i++ {
-		exists, err := sonarr.SeriesExists(ctx, seriesID)
-		if err != nil {
-			return err
-		}
-		if !exists {
-			return nil
-		}
-		logging.L().Debug().Int("sonarr_id", seriesID).Int("attempt", i+1).Msg("Series still exists after delete; waiting")
-		time.Sleep(delay)
-	}
-	return fmt.Errorf("series still exists after delete (sonarr_id=%d)", seriesID)
+	return outcome, nil
 }
 
 func formatOptionalTime(val *time.Time) string {
@@ -0,0 +1,204 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Entry struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Command      string    `json:"command,omitempty"`
+	Actor        string    `json:"actor,omitempty"`
+	Action       string    `json:"action"`
+	Type         string    `json:"type"`
+	Title        string    `json:"title"`
+	Instance     string    `json:"instance,omitempty"`
+	RadarrID     *int      `json:"radarr_id,omitempty"`
+	SonarrID     *int      `json:"sonarr_id,omitempty"`
+	TMDBID       *int      `json:"tmdb_id,omitempty"`
+	TVDBID       *int      `json:"tvdb_id,omitempty"`
+	IMDBID       string    `json:"imdb_id,omitempty"`
+	SeasonNumber *int      `json:"season_number,omitempty"`
+	Path         string    `json:"path,omitempty"`
+	Destination  string    `json:"destination,omitempty"`
+	Torrents     []string  `json:"torrents_removed,omitempty"`
+	BytesFreed   int64     `json:"bytes_freed"`
+	FileIDs      []int     `json:"file_ids,omitempty"`
+	Report       string    `json:"report,omitempty"`
+	Success      bool      `json:"success"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type Origin struct {
+	Command string
+	Report  string
+}
+
+type Journal struct {
+	path   string
+	origin Origin
+	actor  string
+	mu     sync.Mutex
+}
+
+type Filter struct {
+	Title  string
+	Action string
+	Type   string
+	Since  time.Time
+	Until  time.Time
+	Failed bool
+}
+
+type MonthTotal struct {
+	Month      string
+	Actions    int
+	BytesFreed int64
+}
+
+func Open(path string, origin Origin) *Journal {
+	if path == "" {
+		return nil
+	}
+	return &Journal{path: path, origin: origin, actor: currentActor()}
+}
+
+func (j *Journal) Record(entry Entry) error {
+	if j == nil {
+		return nil
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+	if entry.Command == "" {
+		entry.Command = j.origin.Command
+	}
+	if entry.Report == "" {
+		entry.Report = j.origin.Report
+	}
+	if entry.Actor == "" {
+		entry.Actor = j.actor
+	}
+
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal journal entry: %w", err)
+	}
+	payload = append(payload, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open journal: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(payload); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	return nil
+}
+
+func Read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(text), &entry); err != nil {
+			return nil, fmt.Errorf("parse journal line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+	return entries, nil
+}
+
+func (f Filter) Match(entry Entry) bool {
+	if f.Title != "" && !strings.Contains(strings.ToLower(entry.Title), strings.ToLower(f.Title)) {
+		return false
+	}
+	if f.Action != "" && !strings.EqualFold(entry.Action, f.Action) {
+		return false
+	}
+	if f.Type != "" && !strings.EqualFold(entry.Type, f.Type) {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Timestamp.Before(f.Until) {
+		return false
+	}
+	if f.Failed && entry.Success {
+		return false
+	}
+	return true
+}
+
+func Select(entries []Entry, filter Filter) []Entry {
+	out := []Entry{}
+	for _, entry := range entries {
+		if filter.Match(entry) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func MonthlyTotals(entries []Entry) []MonthTotal {
+	byMonth := map[string]*MonthTotal{}
+	for _, entry := range entries {
+		if !entry.Success {
+			continue
+		}
+		month := entry.Timestamp.UTC().Format("2006-01")
+		total, ok := byMonth[month]
+		if !ok {
+			total = &MonthTotal{Month: month}
+			byMonth[month] = total
+		}
+		total.Actions++
+		total.BytesFreed += entry.BytesFreed
+	}
+	out := make([]MonthTotal, 0, len(byMonth))
+	for _, total := range byMonth {
+		out = append(out, *total)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Month < out[j].Month
+	})
+	return out
+}
+
+func currentActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
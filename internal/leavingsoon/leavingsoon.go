@@ -0,0 +1,141 @@
+// Package leavingsoon warns household users before media goes: items that
+// scheduled scans keep flagging are put into a Plex "Leaving Soon" collection,
+// and apply holds back deleting them until they have spent the grace period
+// there without being watched.
+package leavingsoon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/state"
+)
+
+const stateFile = "leaving-soon.json"
+
+// State lists the items leaving soon, keyed by report.Item.Key.
+type State struct {
+	UpdatedAt time.Time        `json:"updated_at"`
+	Items     map[string]Entry `json:"items"`
+}
+
+type Entry struct {
+	Title string `json:"title"`
+	Type  string `json:"type"`
+	// FirstFlagged is when the first of the consecutive scans that flagged
+	// the item ran.
+	FirstFlagged time.Time `json:"first_flagged"`
+	AddedAt      time.Time `json:"added_at"`
+	// PlexKey is the rating key of the movie or show in the collection; it is
+	// empty when the item was not found in Plex.
+	PlexKey string `json:"plex_rating_key,omitempty"`
+}
+
+// Flagged reports how many consecutive scans have flagged an item and when
+// the first of them ran.
+type Flagged func(item report.Item) (scans int, since time.Time)
+
+func Load(dir state.Dir) (*State, error) {
+	st := &State{Items: map[string]Entry{}}
+	if _, err := dir.ReadJSON(stateFile, st); err != nil {
+		return nil, err
+	}
+	if st.Items == nil {
+		st.Items = map[string]Entry{}
+	}
+	return st, nil
+}
+
+// DeleteAfter is when the entry's grace period ends.
+func (e Entry) DeleteAfter(cfg config.LeavingSoon) time.Time {
+	return e.AddedAt.AddDate(0, 0, cfg.GraceDays)
+}
+
+// Synced reports whether a serve scan has ever updated the state. Only the
+// daemon fills it, so without one every item would be held forever.
+func (s *State) Synced() bool {
+	return !s.UpdatedAt.IsZero()
+}
+
+// Hold returns why a destructive action on item has to wait, or "" once the
+// item has spent the grace period in the collection unwatched. Items that
+// could not be matched in Plex are held until the match succeeds, since
+// nobody was warned about them.
+func (s *State) Hold(item report.Item, cfg config.LeavingSoon, now time.Time) string {
+	entry, ok := s.Items[item.Key()]
+	if !ok {
+		return "not in Leaving Soon yet"
+	}
+	if watchedSince(item, entry.AddedAt) {
+		return "watched while leaving soon"
+	}
+	if entry.PlexKey == "" {
+		return "not found in Plex, so never shown in Leaving Soon"
+	}
+	if until := entry.DeleteAfter(cfg); now.Before(until) {
+		return fmt.Sprintf("leaving soon until %s", until.Format("2006-01-02"))
+	}
+	return ""
+}
+
+// Sync updates the state from a scan report and makes the Plex collections
+// match it: items flagged by enough consecutive scans are added, and items no
+// longer flagged or watched since they were added are removed. It returns the
+// keys of the watched items so their streaks can start over.
+func Sync(ctx context.Context, cfg config.Config, dir state.Dir, rep *report.Report, flagged Flagged) ([]string, error) {
+	log := logging.L()
+	st, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]report.Item{}
+	for _, item := range rep.Items {
+		if item.Action == report.ActionKeep {
+			continue
+		}
+		current[item.Key()] = item
+	}
+
+	var rescued []string
+	for key, entry := range st.Items {
+		item, ok := current[key]
+		switch {
+		case !ok:
+			log.Info().Str("title", entry.Title).Msg("No longer flagged; removing from Leaving Soon")
+			delete(st.Items, key)
+		case watchedSince(item, entry.AddedAt):
+			log.Info().Str("title", entry.Title).Msg("Watched while leaving soon; removing from Leaving Soon")
+			delete(st.Items, key)
+			delete(current, key)
+			rescued = append(rescued, key)
+		}
+	}
+	for key, item := range current {
+		if _, ok := st.Items[key]; ok {
+			continue
+		}
+		scans, since := flagged(item)
+		if scans < cfg.LeavingSoon.Scans {
+			continue
+		}
+		log.Info().Str("title", item.Title).Int("scans", scans).Msg("Adding to Leaving Soon")
+		st.Items[key] = Entry{Title: item.Title, Type: item.Type, FirstFlagged: since, AddedAt: rep.GeneratedAt}
+	}
+
+	// Keep the state even when Plex is unreachable so grace periods are not
+	// restarted by the next scan.
+	plexErr := syncPlex(ctx, cfg, st, current)
+	st.UpdatedAt = rep.GeneratedAt
+	if err := dir.WriteJSON(stateFile, st); err != nil {
+		return rescued, err
+	}
+	return rescued, plexErr
+}
+
+func watchedSince(item report.Item, since time.Time) bool {
+	return item.LastActivityAt != nil && item.LastActivityAt.After(since)
+}
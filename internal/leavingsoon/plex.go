@@ -0,0 +1,212 @@
+package leavingsoon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+const (
+	plexTypeMovie = 1
+	plexTypeShow  = 2
+)
+
+type section struct {
+	key      string
+	title    string
+	itemType int
+}
+
+type plexItem struct {
+	ratingKey string
+	section   string
+}
+
+// syncPlex matches entries to Plex movies and shows by external ID and makes
+// the collection in each library section hold exactly those. Seasons are
+// represented by their show.
+func syncPlex(ctx context.Context, cfg config.Config, st *State, items map[string]report.Item) error {
+	log := logging.L()
+	plex, err := clients.NewPlexClient(cfg.Plex.BaseURL, cfg.Plex.APIKey)
+	if err != nil {
+		return err
+	}
+	machineID, err := plex.MachineIdentifier(ctx)
+	if err != nil {
+		return err
+	}
+	all, err := plex.Sections(ctx)
+	if err != nil {
+		return err
+	}
+	var sections []section
+	library := map[string]plexItem{}
+	for _, s := range all {
+		sec := section{key: s.Key, title: s.Title}
+		switch s.Type {
+		case "movie":
+			sec.itemType = plexTypeMovie
+		case "show":
+			sec.itemType = plexTypeShow
+		default:
+			continue
+		}
+		sections = append(sections, sec)
+		entries, err := plex.SectionItems(ctx, s.Key, sec.itemType)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			for _, guid := range guids(entry) {
+				library[s.Type+"|"+guid] = plexItem{ratingKey: entry.RatingKey, section: s.Key}
+			}
+		}
+	}
+
+	want := map[string]map[string]bool{}
+	for key, entry := range st.Items {
+		match, ok := lookup(library, items[key])
+		if !ok {
+			log.Warn().Str("title", entry.Title).Msg("Not found in Plex; holding without adding to the collection")
+			entry.PlexKey = ""
+			st.Items[key] = entry
+			continue
+		}
+		entry.PlexKey = match.ratingKey
+		st.Items[key] = entry
+		if want[match.section] == nil {
+			want[match.section] = map[string]bool{}
+		}
+		want[match.section][match.ratingKey] = true
+	}
+
+	for _, sec := range sections {
+		if err := syncCollection(ctx, plex, machineID, sec, cfg.LeavingSoon.Collection, want[sec.key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func syncCollection(ctx context.Context, plex *clients.PlexClient, machineID string, sec section, title string, want map[string]bool) error {
+	log := logging.L().With().Str("section", sec.title).Str("collection", title).Logger()
+	collections, err := plex.Collections(ctx, sec.key)
+	if err != nil {
+		return err
+	}
+	collectionKey := ""
+	for _, c := range collections {
+		if c.Title == title {
+			collectionKey = c.RatingKey
+			break
+		}
+	}
+	if collectionKey == "" {
+		if len(want) == 0 {
+			return nil
+		}
+		if _, err := plex.CreateCollection(ctx, machineID, sec.key, sec.itemType, title, sortedKeys(want)); err != nil {
+			return err
+		}
+		log.Info().Int("count", len(want)).Msg("Created Plex collection")
+		return nil
+	}
+
+	members, err := plex.CollectionItems(ctx, collectionKey)
+	if err != nil {
+		return err
+	}
+	have := map[string]bool{}
+	for _, member := range members {
+		have[member.RatingKey] = true
+		if want[member.RatingKey] {
+			continue
+		}
+		if err := plex.RemoveFromCollection(ctx, collectionKey, member.RatingKey); err != nil {
+			return err
+		}
+		log.Debug().Str("title", member.Title).Msg("Removed from Plex collection")
+	}
+	var add []string
+	for key := range want {
+		if !have[key] {
+			add = append(add, key)
+		}
+	}
+	if len(add) == 0 {
+		return nil
+	}
+	sort.Strings(add)
+	if err := plex.AddToCollection(ctx, machineID, collectionKey, add); err != nil {
+		return err
+	}
+	log.Debug().Int("count", len(add)).Msg("Added to Plex collection")
+	return nil
+}
+
+func lookup(library map[string]plexItem, item report.Item) (plexItem, bool) {
+	plexType := "show"
+	if item.Type == "movie" {
+		plexType = "movie"
+	}
+	var ids []string
+	if item.TMDBID != nil {
+		ids = append(ids, fmt.Sprintf("tmdb://%d", *item.TMDBID))
+	}
+	if item.TVDBID != nil {
+		ids = append(ids, fmt.Sprintf("tvdb://%d", *item.TVDBID))
+	}
+	if item.IMDBID != "" {
+		ids = append(ids, "imdb://"+item.IMDBID)
+	}
+	for _, id := range ids {
+		if match, ok := library[plexType+"|"+id]; ok {
+			return match, true
+		}
+	}
+	return plexItem{}, false
+}
+
+// guids returns an item's external IDs as tmdb://, tvdb:// or imdb:// URIs,
+// normalizing the legacy agent form (com.plexapp.agents.thetvdb://123?lang=en).
+func guids(item clients.PlexMetadata) []string {
+	raw := make([]string, 0, len(item.Guids)+1)
+	for _, g := range item.Guids {
+		raw = append(raw, g.ID)
+	}
+	raw = append(raw, item.Guid)
+	var out []string
+	for _, guid := range raw {
+		guid = strings.TrimPrefix(guid, "com.plexapp.agents.")
+		guid, _, _ = strings.Cut(guid, "?")
+		provider, id, ok := strings.Cut(guid, "://")
+		if !ok || id == "" {
+			continue
+		}
+		id, _, _ = strings.Cut(id, "/")
+		switch provider {
+		case "tmdb", "themoviedb":
+			out = append(out, "tmdb://"+id)
+		case "tvdb", "thetvdb":
+			out = append(out, "tvdb://"+id)
+		case "imdb":
+			out = append(out, "imdb://"+id)
+		}
+	}
+	return out
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"go-unraid-clean/internal/config"
+)
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+type webhook struct {
+	cfg  config.NotifySink
+	body *template.Template
+}
+
+func newWebhook(cfg config.NotifySink) (*webhook, error) {
+	w := &webhook{cfg: cfg}
+	if cfg.Body == "" {
+		return w, nil
+	}
+	tmpl, err := template.New(cfg.Name).Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"gib": func(bytes int64) string {
+			return fmt.Sprintf("%.1f", gib(bytes))
+		},
+	}).Parse(cfg.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: body: %w", cfg.Name, err)
+	}
+	w.body = tmpl
+	return w, nil
+}
+
+func (w *webhook) Name() string { return w.cfg.Name }
+
+func (w *webhook) Send(ctx context.Context, s Summary) error {
+	var body bytes.Buffer
+	if w.body != nil {
+		if err := w.body.Execute(&body, s); err != nil {
+			return fmt.Errorf("%s: body: %w", w.cfg.Name, err)
+		}
+	} else if err := json.NewEncoder(&body).Encode(s); err != nil {
+		return err
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	for key, value := range w.cfg.Headers {
+		headers[key] = value
+	}
+	return post(ctx, w.cfg.Name, w.cfg.URL, headers, body.Bytes())
+}
+
+type discord struct {
+	cfg config.NotifySink
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Timestamp   string         `json:"timestamp"`
+	Fields      []discordField `json:"fields"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+const (
+	discordGreen = 0x2ecc71
+	discordRed   = 0xe74c3c
+)
+
+func (d *discord) Name() string { return d.cfg.Name }
+
+func (d *discord) Send(ctx context.Context, s Summary) error {
+	embed := discordEmbed{
+		Title:     s.Subject(),
+		Color:     discordGreen,
+		Timestamp: s.Time.Format(time.RFC3339),
+		Fields: []discordField{
+			{Name: "Items", Value: strconv.Itoa(s.Items), Inline: true},
+			{Name: "Reclaimable", Value: fmt.Sprintf("%.1f GiB", s.ReclaimableGiB()), Inline: true},
+		},
+	}
+	if s.applied() {
+		embed.Fields[0].Name = "Applied"
+		embed.Fields[1] = discordField{Name: "Freed", Value: fmt.Sprintf("%.1f GiB", s.FreedGiB()), Inline: true}
+	}
+	// Discord rejects descriptions over 4096 and field values over 1024
+	// characters.
+	if len(s.Top) > 0 {
+		embed.Description = truncate("**Largest**\n"+s.topLines(), 4096)
+	}
+	if s.Results != nil {
+		embed.Fields = append(embed.Fields, discordField{Name: "Results", Value: s.Results.String()})
+	}
+	if len(s.Failures) > 0 {
+		embed.Color = discordRed
+		embed.Fields = append(embed.Fields, discordField{Name: "Failures", Value: truncate("- "+strings.Join(s.Failures, "\n- "), 1024)})
+	}
+	body, err := json.Marshal(map[string]any{
+		"username": "go-unraid-clean",
+		"embeds":   []discordEmbed{embed},
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, d.cfg.Name, d.cfg.URL, map[string]string{"Content-Type": "application/json"}, body)
+}
+
+type ntfy struct {
+	cfg config.NotifySink
+}
+
+func (n *ntfy) Name() string { return n.cfg.Name }
+
+func (n *ntfy) Send(ctx context.Context, s Summary) error {
+	headers := map[string]string{
+		"Title": s.Subject(),
+		"Tags":  "wastebasket",
+	}
+	if len(s.Failures) > 0 {
+		headers["Tags"] = "warning"
+	}
+	if n.cfg.Priority > 0 {
+		headers["Priority"] = strconv.Itoa(n.cfg.Priority)
+	}
+	if n.cfg.Token != "" {
+		headers["Authorization"] = "Bearer " + n.cfg.Token
+	}
+	url := strings.TrimRight(n.cfg.URL, "/") + "/" + n.cfg.Topic
+	return post(ctx, n.cfg.Name, url, headers, []byte(s.Text()))
+}
+
+type gotify struct {
+	cfg config.NotifySink
+}
+
+func (g *gotify) Name() string { return g.cfg.Name }
+
+func (g *gotify) Send(ctx context.Context, s Summary) error {
+	body, err := json.Marshal(struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority,omitempty"`
+	}{Title: s.Subject(), Message: s.Text(), Priority: g.cfg.Priority})
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"X-Gotify-Key": g.cfg.Token,
+	}
+	return post(ctx, g.cfg.Name, strings.TrimRight(g.cfg.URL, "/")+"/message", headers, body)
+}
+
+func post(ctx context.Context, label, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 2000))
+		return fmt.Errorf("%s: status %d: %s", label, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// truncate shortens s to at most limit characters, cutting on a rune
+// boundary so multi-byte titles stay valid UTF-8.
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit-1]) + "…"
+}
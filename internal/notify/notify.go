@@ -0,0 +1,269 @@
+// Package notify pushes a summary of a finished scan, apply or interactive
+// review to the sinks configured under notify: webhooks, Discord, ntfy,
+// Gotify and email.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-unraid-clean/internal/apply"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+// EventTest marks the sample summary sent by the notify test command.
+const EventTest = "test"
+
+// Summary is what every sink reports. It is also the data the webhook body
+// template is rendered with. Scan summaries describe the flagged items and
+// what deleting them would reclaim; apply and review summaries describe the
+// items acted on and the space that actually freed.
+type Summary struct {
+	Event            string    `json:"event"`
+	Time             time.Time `json:"time"`
+	Report           string    `json:"report,omitempty"`
+	Items            int       `json:"items"`
+	ReclaimableBytes int64     `json:"reclaimable_bytes,omitempty"`
+	FreedBytes       int64     `json:"freed_bytes,omitempty"`
+	// Top lists the largest items by reclaimable or freed bytes.
+	Top []Item `json:"top"`
+	// Results is set for apply and interactive review.
+	Results  *Results `json:"results,omitempty"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+type Item struct {
+	Title    string `json:"title"`
+	Type     string `json:"type"`
+	Instance string `json:"instance,omitempty"`
+	Action   string `json:"action,omitempty"`
+	Bytes    int64  `json:"bytes"`
+}
+
+type Results struct {
+	Done    int `json:"done"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Kept    int `json:"kept"`
+}
+
+// ScanSummary summarizes the items a scan flagged, listing the top largest
+// by reclaimable size.
+func ScanSummary(rep *report.Report, reportPath string, top int) Summary {
+	stats := report.Summarize(rep)
+	items := make([]Item, 0, len(rep.Items))
+	for _, item := range rep.Items {
+		items = append(items, newItem(item, item.Reclaimable()))
+	}
+	return Summary{
+		Event:            config.NotifyEventScan,
+		Time:             time.Now().UTC(),
+		Report:           reportPath,
+		Items:            stats.Total,
+		ReclaimableBytes: stats.ReclaimableBytes,
+		Top:              largest(items, top),
+	}
+}
+
+// ApplySummary summarizes an apply or interactive review: the items acted on,
+// the space that freed and the failures, each error joined into err becoming
+// one line.
+func ApplySummary(event string, applied []apply.Applied, reportPath string, top int, results Results, err error) Summary {
+	s := Summary{
+		Event:   event,
+		Time:    time.Now().UTC(),
+		Report:  reportPath,
+		Items:   len(applied),
+		Results: &results,
+	}
+	items := make([]Item, 0, len(applied))
+	for _, a := range applied {
+		s.FreedBytes += a.BytesFreed
+		items = append(items, newItem(a.Item, a.BytesFreed))
+	}
+	s.Top = largest(items, top)
+	s.Failures = errorLines(err)
+	return s
+}
+
+func newItem(item report.Item, bytes int64) Item {
+	return Item{
+		Title:    item.Title,
+		Type:     item.Type,
+		Instance: item.Instance,
+		Action:   item.Action,
+		Bytes:    bytes,
+	}
+}
+
+func largest(items []Item, top int) []Item {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Bytes > items[j].Bytes
+	})
+	if len(items) > top {
+		items = items[:top]
+	}
+	return items
+}
+
+func (s Summary) ReclaimableGiB() float64 {
+	return gib(s.ReclaimableBytes)
+}
+
+func (s Summary) FreedGiB() float64 {
+	return gib(s.FreedBytes)
+}
+
+// applied reports whether s describes actions taken rather than a scan.
+func (s Summary) applied() bool {
+	return s.Results != nil
+}
+
+// Size describes the summary's bytes: what a scan would reclaim or what an
+// apply freed.
+func (s Summary) Size() string {
+	if s.applied() {
+		return fmt.Sprintf("%.1f GiB freed", s.FreedGiB())
+	}
+	return fmt.Sprintf("%.1f GiB reclaimable", s.ReclaimableGiB())
+}
+
+// Subject is a one-line headline for titles and email subjects.
+func (s Summary) Subject() string {
+	var subject string
+	switch s.Event {
+	case config.NotifyEventApply:
+		subject = "apply finished"
+	case config.NotifyEventInteractive:
+		subject = "interactive review finished"
+	case EventTest:
+		subject = "test notification"
+	default:
+		subject = "scan finished"
+	}
+	noun := "items"
+	if s.applied() {
+		noun = "items applied"
+	}
+	subject = fmt.Sprintf("go-unraid-clean %s: %d %s, %s", subject, s.Items, noun, s.Size())
+	if len(s.Failures) > 0 {
+		subject += fmt.Sprintf(", %d failures", len(s.Failures))
+	}
+	return subject
+}
+
+// Text is the plain-text body used by ntfy, Gotify and email.
+func (s Summary) Text() string {
+	var b strings.Builder
+	if s.applied() {
+		fmt.Fprintf(&b, "Applied: %d\n", s.Items)
+		fmt.Fprintf(&b, "Freed: %.1f GiB\n", s.FreedGiB())
+		fmt.Fprintf(&b, "%s\n", s.Results)
+	} else {
+		fmt.Fprintf(&b, "Items: %d\n", s.Items)
+		fmt.Fprintf(&b, "Reclaimable: %.1f GiB\n", s.ReclaimableGiB())
+	}
+	if s.Report != "" {
+		fmt.Fprintf(&b, "Report: %s\n", s.Report)
+	}
+	if len(s.Top) > 0 {
+		fmt.Fprintf(&b, "\nLargest:\n%s", s.topLines())
+	}
+	if len(s.Failures) > 0 {
+		b.WriteString("\nFailures:\n")
+		for _, failure := range s.Failures {
+			fmt.Fprintf(&b, "- %s\n", failure)
+		}
+	}
+	return b.String()
+}
+
+func (r Results) String() string {
+	return fmt.Sprintf("Done: %d, failed: %d, skipped: %d, kept: %d", r.Done, r.Failed, r.Skipped, r.Kept)
+}
+
+func (s Summary) topLines() string {
+	var b strings.Builder
+	for _, item := range s.Top {
+		title := item.Title
+		if item.Instance != "" {
+			title += " [" + item.Instance + "]"
+		}
+		if item.Action != "" && item.Action != report.ActionDelete {
+			title += " - " + strings.ReplaceAll(item.Action, "_", " ")
+		}
+		fmt.Fprintf(&b, "- %s (%s) %.1f GiB\n", title, item.Type, gib(item.Bytes))
+	}
+	return b.String()
+}
+
+// Sink delivers summaries to one configured target.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, s Summary) error
+}
+
+// New builds the sink for one notify.sinks entry.
+func New(cfg config.NotifySink) (Sink, error) {
+	switch cfg.Type {
+	case config.NotifySinkWebhook:
+		return newWebhook(cfg)
+	case config.NotifySinkDiscord:
+		return &discord{cfg: cfg}, nil
+	case config.NotifySinkNtfy:
+		return &ntfy{cfg: cfg}, nil
+	case config.NotifySinkGotify:
+		return &gotify{cfg: cfg}, nil
+	case config.NotifySinkSMTP:
+		return &smtpSink{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unsupported notify sink %q", cfg.Type)
+	}
+}
+
+// Send delivers s to every sink when cfg notifies s.Event. Notifications are
+// best effort: failures are logged and never fail the command that finished.
+func Send(ctx context.Context, cfg config.Notify, s Summary) {
+	if !cfg.Notifies(s.Event) {
+		return
+	}
+	log := logging.L()
+	for _, sinkCfg := range cfg.Sinks {
+		sink, err := New(sinkCfg)
+		if err == nil {
+			err = sink.Send(ctx, s)
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("sink", sinkCfg.Name).Msg("Notification failed")
+			continue
+		}
+		log.Debug().Str("sink", sinkCfg.Name).Str("event", s.Event).Msg("Sent notification")
+	}
+}
+
+func errorLines(err error) []string {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var out []string
+		for _, e := range joined.Unwrap() {
+			out = append(out, errorLines(e)...)
+		}
+		return out
+	}
+	if errors.Is(err, context.Canceled) {
+		return []string{"interrupted"}
+	}
+	return []string{err.Error()}
+}
+
+func gib(bytes int64) float64 {
+	return float64(bytes) / (1024 * 1024 * 1024)
+}
@@ -0,0 +1,264 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"go-unraid-clean/internal/apply"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/report"
+)
+
+const gibBytes = 1024 * 1024 * 1024
+
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   string
+}
+
+// standIn records every request it receives and answers with status.
+func standIn(t *testing.T, status int) (*httptest.Server, *[]request) {
+	t.Helper()
+	var got []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, request{method: r.Method, path: r.URL.Path, header: r.Header.Clone(), body: string(body)})
+		w.WriteHeader(status)
+		if status >= 300 {
+			io.WriteString(w, "nope")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func send(t *testing.T, cfg config.NotifySink, s Summary) error {
+	t.Helper()
+	sink, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sink.Send(context.Background(), s)
+}
+
+func scanSummary() Summary {
+	return Summary{
+		Event:            config.NotifyEventScan,
+		Time:             time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC),
+		Items:            2,
+		ReclaimableBytes: 60 * gibBytes,
+		Top: []Item{
+			{Title: "Big Movie", Type: "movie", Action: "delete", Bytes: 45 * gibBytes},
+			{Title: "Show", Type: "series", Action: "delete", Bytes: 15 * gibBytes},
+		},
+	}
+}
+
+func TestWebhookDefaultBody(t *testing.T) {
+	srv, got := standIn(t, http.StatusNoContent)
+	err := send(t, config.NotifySink{
+		Name:    "hook",
+		Type:    config.NotifySinkWebhook,
+		URL:     srv.URL + "/hook",
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	}, scanSummary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := (*got)[0]
+	if req.method != http.MethodPost || req.path != "/hook" {
+		t.Errorf("request = %s %s, want POST /hook", req.method, req.path)
+	}
+	if req.header.Get("Authorization") != "Bearer secret" || req.header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v", req.header)
+	}
+	var decoded Summary
+	if err := json.Unmarshal([]byte(req.body), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Items != 2 || decoded.ReclaimableBytes != 60*gibBytes || len(decoded.Top) != 2 {
+		t.Errorf("body = %s", req.body)
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	srv, got := standIn(t, http.StatusOK)
+	err := send(t, config.NotifySink{
+		Name: "hook",
+		Type: config.NotifySinkWebhook,
+		URL:  srv.URL,
+		Body: `{"text": {{json .Subject}}, "gib": {{gib .ReclaimableBytes}}, "first": {{json (index .Top 0).Title}}}`,
+	}, scanSummary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"text": "go-unraid-clean scan finished: 2 items, 60.0 GiB reclaimable", "gib": 60.0, "first": "Big Movie"}`
+	if body := (*got)[0].body; body != want {
+		t.Errorf("body = %s\nwant %s", body, want)
+	}
+}
+
+func TestWebhookTemplateErrors(t *testing.T) {
+	if _, err := New(config.NotifySink{Name: "hook", Type: config.NotifySinkWebhook, URL: "http://x", Body: "{{.Items"}); err == nil {
+		t.Error("New() with an unclosed action succeeded")
+	}
+	err := send(t, config.NotifySink{Name: "hook", Type: config.NotifySinkWebhook, URL: "http://127.0.0.1:1", Body: "{{.Missing}}"}, scanSummary())
+	if err == nil || !strings.Contains(err.Error(), "hook: body") {
+		t.Errorf("Send() error = %v, want a body error", err)
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	srv, got := standIn(t, http.StatusNoContent)
+	s := scanSummary()
+	s.Failures = []string{strings.Repeat("é", 2000)}
+	if err := send(t, config.NotifySink{Name: "discord", Type: config.NotifySinkDiscord, URL: srv.URL + "/api/webhooks/1/x"}, s); err != nil {
+		t.Fatal(err)
+	}
+	var payload struct {
+		Username string         `json:"username"`
+		Embeds   []discordEmbed `json:"embeds"`
+	}
+	if err := json.Unmarshal([]byte((*got)[0].body), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Username != "go-unraid-clean" || len(payload.Embeds) != 1 {
+		t.Fatalf("payload = %+v", payload)
+	}
+	embed := payload.Embeds[0]
+	if embed.Color != discordRed {
+		t.Errorf("color = %#x, want red for failures", embed.Color)
+	}
+	if !strings.Contains(embed.Description, "Big Movie (movie) 45.0 GiB") {
+		t.Errorf("description = %q", embed.Description)
+	}
+	failures := embed.Fields[len(embed.Fields)-1]
+	if failures.Name != "Failures" || utf8.RuneCountInString(failures.Value) != 1024 || !utf8.ValidString(failures.Value) {
+		t.Errorf("failures field has %d runes, valid UTF-8 %v", utf8.RuneCountInString(failures.Value), utf8.ValidString(failures.Value))
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("truncate() = %q", got)
+	}
+	got := truncate(strings.Repeat("日本", 10), 5)
+	if got != "日本日本…" {
+		t.Errorf("truncate() = %q", got)
+	}
+}
+
+func TestNtfy(t *testing.T) {
+	srv, got := standIn(t, http.StatusOK)
+	err := send(t, config.NotifySink{
+		Name:     "ntfy",
+		Type:     config.NotifySinkNtfy,
+		URL:      srv.URL + "/",
+		Topic:    "unraid",
+		Token:    "tk_abc",
+		Priority: 4,
+	}, scanSummary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := (*got)[0]
+	if req.path != "/unraid" {
+		t.Errorf("path = %s, want /unraid", req.path)
+	}
+	if req.header.Get("Authorization") != "Bearer tk_abc" || req.header.Get("Priority") != "4" {
+		t.Errorf("headers = %v", req.header)
+	}
+	if !strings.HasPrefix(req.header.Get("Title"), "go-unraid-clean scan finished") {
+		t.Errorf("title = %q", req.header.Get("Title"))
+	}
+	if !strings.Contains(req.body, "Reclaimable: 60.0 GiB") || !strings.Contains(req.body, "- Big Movie (movie) 45.0 GiB") {
+		t.Errorf("body = %s", req.body)
+	}
+}
+
+func TestGotify(t *testing.T) {
+	srv, got := standIn(t, http.StatusOK)
+	err := send(t, config.NotifySink{Name: "gotify", Type: config.NotifySinkGotify, URL: srv.URL, Token: "app-token", Priority: 6}, scanSummary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := (*got)[0]
+	if req.path != "/message" || req.header.Get("X-Gotify-Key") != "app-token" {
+		t.Errorf("request = %s %v", req.path, req.header)
+	}
+	var msg struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+	}
+	if err := json.Unmarshal([]byte(req.body), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Priority != 6 || !strings.Contains(msg.Message, "Items: 2") || msg.Title == "" {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestNon2xx(t *testing.T) {
+	srv, _ := standIn(t, http.StatusUnauthorized)
+	for _, cfg := range []config.NotifySink{
+		{Name: "hook", Type: config.NotifySinkWebhook, URL: srv.URL},
+		{Name: "discord", Type: config.NotifySinkDiscord, URL: srv.URL},
+		{Name: "ntfy", Type: config.NotifySinkNtfy, URL: srv.URL, Topic: "t"},
+		{Name: "gotify", Type: config.NotifySinkGotify, URL: srv.URL, Token: "x"},
+	} {
+		err := send(t, cfg, scanSummary())
+		if err == nil || err.Error() != cfg.Name+": status 401: nope" {
+			t.Errorf("%s: Send() error = %v", cfg.Name, err)
+		}
+	}
+}
+
+func TestApplySummary(t *testing.T) {
+	applied := []apply.Applied{
+		{Item: report.Item{Title: "Ignored", Type: "movie", Action: report.ActionIgnore}},
+		{Item: report.Item{Title: "Season", Type: "season", Action: report.ActionDelete, SizeBytes: 90 * gibBytes}, BytesFreed: 10 * gibBytes},
+		{Item: report.Item{Title: "Movie", Type: "movie", Action: report.ActionDelete, SizeBytes: 20 * gibBytes}, BytesFreed: 20 * gibBytes},
+	}
+	err := errors.Join(errors.New(`movie "A": boom`), errors.New(`movie "B": bang`))
+	s := ApplySummary(config.NotifyEventApply, applied, "review.json", 2, Results{Done: 3, Failed: 2}, err)
+
+	if s.Items != 3 || s.FreedBytes != 30*gibBytes || s.ReclaimableBytes != 0 {
+		t.Errorf("summary = %+v", s)
+	}
+	if len(s.Top) != 2 || s.Top[0].Title != "Movie" || s.Top[1].Title != "Season" {
+		t.Errorf("top = %+v, want freed bytes order", s.Top)
+	}
+	if len(s.Failures) != 2 {
+		t.Errorf("failures = %v", s.Failures)
+	}
+	if want := "go-unraid-clean apply finished: 3 items applied, 30.0 GiB freed, 2 failures"; s.Subject() != want {
+		t.Errorf("Subject() = %q, want %q", s.Subject(), want)
+	}
+}
+
+func TestScanSummary(t *testing.T) {
+	reclaimable := int64(5 * gibBytes)
+	rep := &report.Report{Items: []report.Item{
+		{Title: "Small", Type: "movie", SizeBytes: 1 * gibBytes},
+		{Title: "Linked", Type: "movie", SizeBytes: 50 * gibBytes, ReclaimableBytes: &reclaimable},
+		{Title: "Large", Type: "movie", SizeBytes: 10 * gibBytes},
+	}}
+	s := ScanSummary(rep, "scan.json", 2)
+	if s.Items != 3 || s.ReclaimableBytes != 16*gibBytes {
+		t.Errorf("summary = %+v", s)
+	}
+	if len(s.Top) != 2 || s.Top[0].Title != "Large" || s.Top[1].Title != "Linked" {
+		t.Errorf("top = %+v, want reclaimable order", s.Top)
+	}
+}
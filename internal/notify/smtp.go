@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-unraid-clean/internal/config"
+)
+
+type smtpSink struct {
+	cfg config.NotifySink
+}
+
+func (m *smtpSink) Name() string { return m.cfg.Name }
+
+// Send mails the summary. Port 465 uses implicit TLS; other ports upgrade
+// with STARTTLS when the server offers it. Credentials are only sent over
+// TLS or to localhost.
+func (m *smtpSink) Send(ctx context.Context, s Summary) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: m.cfg.Host}
+
+	var conn net.Conn
+	var err error
+	if m.cfg.Port == 465 {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", m.cfg.Name, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("%s: %w", m.cfg.Name, err)
+	}
+	defer client.Close()
+
+	if err := m.deliver(client, tlsConfig, s); err != nil {
+		return fmt.Errorf("%s: %w", m.cfg.Name, err)
+	}
+	return nil
+}
+
+func (m *smtpSink) deliver(client *smtp.Client, tlsConfig *tls.Config, s Summary) error {
+	if ok, _ := client.Extension("STARTTLS"); ok && m.cfg.Port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if m.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range m.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(m.message(s)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (m *smtpSink) message(s Summary) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(m.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", s.Subject())
+	fmt.Fprintf(&b, "Date: %s\r\n", s.Time.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(s.Text(), "\n", "\r\n"))
+	return b.Bytes()
+}
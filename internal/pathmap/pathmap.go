@@ -0,0 +1,118 @@
+// Package pathmap translates paths reported by Radarr and Sonarr into paths
+// on the host running go-unraid-clean.
+package pathmap
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"go-unraid-clean/internal/config"
+)
+
+type Mapper struct {
+	mappings []config.PathMapping
+}
+
+// New returns a Mapper that applies the longest matching prefix first.
+func New(mappings []config.PathMapping) *Mapper {
+	cleaned := make([]config.PathMapping, 0, len(mappings))
+	for _, m := range mappings {
+		cleaned = append(cleaned, config.PathMapping{From: path.Clean(m.From), To: path.Clean(m.To)})
+	}
+	sort.SliceStable(cleaned, func(i, j int) bool {
+		return len(cleaned[i].From) > len(cleaned[j].From)
+	})
+	return &Mapper{mappings: cleaned}
+}
+
+// Map rewrites p using the first mapping whose From is p or a parent
+// directory of p. Paths without a matching mapping are returned cleaned.
+func (m *Mapper) Map(p string) string {
+	if p == "" {
+		return ""
+	}
+	clean := path.Clean(p)
+	for _, mapping := range m.mappings {
+		if rest, ok := cutDir(clean, mapping.From); ok {
+			return path.Join(mapping.To, rest)
+		}
+	}
+	return clean
+}
+
+// cutDir returns p relative to dir when dir is p or one of its parents.
+func cutDir(p, dir string) (string, bool) {
+	if p == dir {
+		return "", true
+	}
+	if dir == "/" {
+		return strings.TrimPrefix(p, "/"), strings.HasPrefix(p, "/")
+	}
+	if strings.HasPrefix(p, dir+"/") {
+		return p[len(dir)+1:], true
+	}
+	return "", false
+}
+
+// Set holds a mapper for each Radarr and Sonarr instance, combining its own
+// mappings with the service-wide ones; the instance wins on equal prefixes.
+type Set struct {
+	radarr map[string]*Mapper
+	sonarr map[string]*Mapper
+	// firstRadarr and firstSonarr resolve items without an instance name.
+	firstRadarr string
+	firstSonarr string
+	// defaultRadarr and defaultSonarr apply to instances not in the config.
+	defaultRadarr *Mapper
+	defaultSonarr *Mapper
+}
+
+func FromConfig(cfg config.Config) *Set {
+	s := &Set{
+		radarr:        map[string]*Mapper{},
+		sonarr:        map[string]*Mapper{},
+		firstRadarr:   cfg.Radarr.First(),
+		firstSonarr:   cfg.Sonarr.First(),
+		defaultRadarr: New(cfg.PathMappings.Radarr),
+		defaultSonarr: New(cfg.PathMappings.Sonarr),
+	}
+	for _, inst := range cfg.Radarr {
+		s.radarr[inst.Name] = New(append(append([]config.PathMapping{}, inst.PathMappings...), cfg.PathMappings.Radarr...))
+	}
+	for _, inst := range cfg.Sonarr {
+		s.sonarr[inst.Name] = New(append(append([]config.PathMapping{}, inst.PathMappings...), cfg.PathMappings.Sonarr...))
+	}
+	return s
+}
+
+// Radarr returns the mapper of a Radarr instance; "" selects the first.
+func (s *Set) Radarr(instance string) *Mapper {
+	if instance == "" {
+		instance = s.firstRadarr
+	}
+	if m, ok := s.radarr[instance]; ok {
+		return m
+	}
+	return s.defaultRadarr
+}
+
+// Sonarr returns the mapper of a Sonarr instance; "" selects the first.
+func (s *Set) Sonarr(instance string) *Mapper {
+	if instance == "" {
+		instance = s.firstSonarr
+	}
+	if m, ok := s.sonarr[instance]; ok {
+		return m
+	}
+	return s.defaultSonarr
+}
+
+// ForType returns the mapper of the instance that owns a report item:
+// Radarr for movies, Sonarr for series and seasons.
+func (s *Set) ForType(itemType string, instance string) *Mapper {
+	if itemType == "movie" {
+		return s.Radarr(instance)
+	}
+	return s.Sonarr(instance)
+}
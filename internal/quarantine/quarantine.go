@@ -0,0 +1,169 @@
+package quarantine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const entryFile = "entry.json"
+
+type Entry struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Title         string          `json:"title"`
+	Instance      string          `json:"instance,omitempty"`
+	RadarrID      *int            `json:"radarr_id,omitempty"`
+	SonarrID      *int            `json:"sonarr_id,omitempty"`
+	TMDBID        *int            `json:"tmdb_id,omitempty"`
+	TVDBID        *int            `json:"tvdb_id,omitempty"`
+	IMDBID        string          `json:"imdb_id,omitempty"`
+	OriginalPath  string          `json:"original_path"`
+	LocalPath     string          `json:"local_path,omitempty"`
+	StoredPath    string          `json:"stored_path"`
+	SizeBytes     int64           `json:"size_bytes"`
+	QuarantinedAt time.Time       `json:"quarantined_at"`
+	Resource      json.RawMessage `json:"resource,omitempty"`
+}
+
+// FilesPath is where the item's files live on this host: LocalPath when the
+// Arr path was mapped, otherwise OriginalPath. OriginalPath is what the item
+// is re-added to Radarr/Sonarr with.
+func (e Entry) FilesPath() string {
+	if e.LocalPath != "" {
+		return e.LocalPath
+	}
+	return e.OriginalPath
+}
+
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("quarantine: path is not configured")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("quarantine: create %s: %w", dir, err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) Put(entry Entry) (Entry, error) {
+	if entry.FilesPath() == "" {
+		return entry, fmt.Errorf("quarantine: item has no path")
+	}
+	if _, err := os.Stat(entry.FilesPath()); err != nil {
+		return entry, fmt.Errorf("quarantine: %w", err)
+	}
+	if entry.QuarantinedAt.IsZero() {
+		entry.QuarantinedAt = time.Now().UTC()
+	}
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("%s-%s", entry.Type, entry.QuarantinedAt.Format("20060102-150405"))
+	}
+
+	slot := filepath.Join(s.dir, entry.ID)
+	if err := os.Mkdir(slot, 0o755); err != nil {
+		return entry, fmt.Errorf("quarantine: create slot: %w", err)
+	}
+	entry.StoredPath = filepath.Join(slot, filepath.Base(filepath.Clean(entry.FilesPath())))
+	// Write the entry first so files in a slot can always be listed,
+	// restored and purged, even if the move is interrupted.
+	if err := s.write(entry); err != nil {
+		os.RemoveAll(slot)
+		return entry, err
+	}
+	if err := movePath(entry.FilesPath(), entry.StoredPath); err != nil {
+		// A complete copy whose source could not be removed stays in the
+		// slot with its entry; anything else never reached the slot.
+		if _, statErr := os.Lstat(entry.StoredPath); statErr == nil {
+			return entry, fmt.Errorf("quarantine: move %s: %w (copy kept as %s)", entry.FilesPath(), err, entry.ID)
+		}
+		os.RemoveAll(slot)
+		return entry, fmt.Errorf("quarantine: move %s: %w", entry.FilesPath(), err)
+	}
+	return entry, nil
+}
+
+func (s *Store) List() ([]Entry, error) {
+	dirs, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("quarantine: list: %w", err)
+	}
+	entries := []Entry{}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		entry, err := s.Get(dir.Name())
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].QuarantinedAt.Before(entries[j].QuarantinedAt)
+	})
+	return entries, nil
+}
+
+func (s *Store) Get(id string) (Entry, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, id, entryFile))
+	if err != nil {
+		return Entry{}, fmt.Errorf("quarantine: read %s: %w", id, err)
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("quarantine: parse %s: %w", id, err)
+	}
+	return entry, nil
+}
+
+func (s *Store) Purge(entry Entry) error {
+	if err := os.RemoveAll(filepath.Join(s.dir, entry.ID)); err != nil {
+		return fmt.Errorf("quarantine: purge %s: %w", entry.ID, err)
+	}
+	return nil
+}
+
+func (s *Store) MoveBack(entry Entry) error {
+	if _, err := os.Stat(entry.FilesPath()); err == nil {
+		return fmt.Errorf("quarantine: restore %s: %s already exists", entry.ID, entry.FilesPath())
+	}
+	if err := os.MkdirAll(filepath.Dir(entry.FilesPath()), 0o755); err != nil {
+		return fmt.Errorf("quarantine: restore %s: %w", entry.ID, err)
+	}
+	if err := movePath(entry.StoredPath, entry.FilesPath()); err != nil {
+		return fmt.Errorf("quarantine: restore %s: %w", entry.ID, err)
+	}
+	return nil
+}
+
+// Return moves restored files back into the entry's slot, undoing MoveBack.
+func (s *Store) Return(entry Entry) error {
+	if err := movePath(entry.FilesPath(), entry.StoredPath); err != nil {
+		return fmt.Errorf("quarantine: return %s: %w", entry.ID, err)
+	}
+	return nil
+}
+
+func (s *Store) write(entry Entry) error {
+	payload, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("quarantine: marshal entry: %w", err)
+	}
+	payload = append(payload, '\n')
+	if err := os.WriteFile(filepath.Join(s.dir, entry.ID, entryFile), payload, 0o644); err != nil {
+		return fmt.Errorf("quarantine: write entry: %w", err)
+	}
+	return nil
+}
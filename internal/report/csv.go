@@ -0,0 +1,319 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var topUserPattern = regexp.MustCompile(`\s*([^:]+?):([0-9.]+)h(?:\s|$)`)
+
+const (
+	FormatAuto = "auto"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+func Read(path string, format string) (*Report, error) {
+	switch strings.ToLower(format) {
+	case "", FormatAuto:
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			return ReadCSV(path)
+		}
+		return ReadJSON(path)
+	case FormatJSON:
+		return ReadJSON(path)
+	case FormatCSV:
+		return ReadCSV(path)
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+func ReadCSV(path string) (*Report, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	columns := map[string]int{}
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = idx
+	}
+	if _, ok := columns["type"]; !ok {
+		return nil, fmt.Errorf("read csv: missing type column")
+	}
+
+	// GeneratedAt comes from the generated_at column, never the file time:
+	// saving the CSV in a spreadsheet would make a stale report look fresh.
+	// Without the column it stays zero, which fails --max-report-age.
+	rep := &Report{Items: []Item{}}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("read csv row %d: %w", line, err)
+		}
+		row := csvRow{columns: columns, record: record}
+		if row.isBlank() {
+			continue
+		}
+		item, err := row.item()
+		if err != nil {
+			return nil, fmt.Errorf("read csv row %d: %w", line, err)
+		}
+		generatedAt, err := parseOptionalTime(row.get("generated_at"))
+		if err != nil {
+			return nil, fmt.Errorf("read csv row %d: generated_at: %w", line, err)
+		}
+		if generatedAt != nil {
+			if !rep.GeneratedAt.IsZero() && !rep.GeneratedAt.Equal(*generatedAt) {
+				return nil, fmt.Errorf("read csv row %d: generated_at %s differs from earlier rows; rows come from different reports", line, generatedAt.Format(time.RFC3339))
+			}
+			rep.GeneratedAt = *generatedAt
+		}
+		rep.Items = append(rep.Items, item)
+	}
+	return rep, nil
+}
+
+func Reconcile(base *Report, reviewed *Report) *Report {
+	rows := map[string]Item{}
+	for _, item := range reviewed.Items {
+		rows[item.Key()] = item
+	}
+
+	out := &Report{
+		GeneratedAt: base.GeneratedAt,
+		Items:       make([]Item, 0, len(base.Items)),
+	}
+	for _, item := range base.Items {
+		if row, ok := rows[item.Key()]; ok {
+			item.Action = row.Action
+		} else {
+			item.Action = ActionKeep
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out
+}
+
+type csvRow struct {
+	columns map[string]int
+	record  []string
+}
+
+func (r csvRow) get(names ...string) string {
+	for _, name := range names {
+		idx, ok := r.columns[name]
+		if !ok || idx >= len(r.record) {
+			continue
+		}
+		if val := strings.TrimSpace(r.record[idx]); val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
+func (r csvRow) isBlank() bool {
+	for _, val := range r.record {
+		if strings.TrimSpace(val) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (r csvRow) item() (Item, error) {
+	item := Item{
+		Type:         strings.ToLower(r.get("type")),
+		Title:        r.get("title"),
+		Instance:     r.get("instance"),
+		IMDBID:       r.get("imdb_id"),
+		SeriesStatus: r.get("series_status"),
+		Path:         r.get("path"),
+		LocalPath:    r.get("local_path"),
+		Reason:       r.get("reason"),
+		Protection:   r.get("protection"),
+		RequestedBy:  r.get("requested_by"),
+	}
+	if item.Type == "" {
+		return item, fmt.Errorf("missing type")
+	}
+	for _, name := range strings.Split(r.get("also_in"), ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			item.AlsoIn = append(item.AlsoIn, name)
+		}
+	}
+	for _, name := range strings.Split(r.get("matched_rules"), ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			item.MatchedRules = append(item.MatchedRules, name)
+		}
+	}
+
+	var err error
+	if item.RadarrID, err = parseOptionalInt(r.get("radarr_id")); err != nil {
+		return item, fmt.Errorf("radarr_id: %w", err)
+	}
+	if item.SonarrID, err = parseOptionalInt(r.get("sonarr_id")); err != nil {
+		return item, fmt.Errorf("sonarr_id: %w", err)
+	}
+	if item.TMDBID, err = parseOptionalInt(r.get("tmdb_id")); err != nil {
+		return item, fmt.Errorf("tmdb_id: %w", err)
+	}
+	if item.TVDBID, err = parseOptionalInt(r.get("tvdb_id")); err != nil {
+		return item, fmt.Errorf("tvdb_id: %w", err)
+	}
+	if item.SeasonNumber, err = parseOptionalInt(r.get("season_number")); err != nil {
+		return item, fmt.Errorf("season_number: %w", err)
+	}
+	if raw := r.get("size_bytes"); raw != "" {
+		if item.SizeBytes, err = parseBytes(raw); err != nil {
+			return item, fmt.Errorf("size_bytes: %w", err)
+		}
+	}
+	if raw := r.get("reclaimable_bytes"); raw != "" {
+		reclaimable, err := parseBytes(raw)
+		if err != nil {
+			return item, fmt.Errorf("reclaimable_bytes: %w", err)
+		}
+		item.ReclaimableBytes = &reclaimable
+	}
+	for _, hash := range strings.Split(r.get("torrent_hashes"), ";") {
+		if hash = strings.TrimSpace(hash); hash != "" {
+			item.Torrents = append(item.Torrents, TorrentInfo{Hash: strings.ToLower(hash)})
+		}
+	}
+	if item.Disks, err = parseDisks(r.get("disks")); err != nil {
+		return item, fmt.Errorf("disks: %w", err)
+	}
+	if item.AddedAt, err = parseOptionalTime(r.get("added_at")); err != nil {
+		return item, fmt.Errorf("added_at: %w", err)
+	}
+	if item.FirstActivityAt, err = parseOptionalTime(r.get("first_activity_at")); err != nil {
+		return item, fmt.Errorf("first_activity_at: %w", err)
+	}
+	if item.LastActivityAt, err = parseOptionalTime(r.get("last_activity_at")); err != nil {
+		return item, fmt.Errorf("last_activity_at: %w", err)
+	}
+	if item.RequestedAt, err = parseOptionalTime(r.get("requested_at")); err != nil {
+		return item, fmt.Errorf("requested_at: %w", err)
+	}
+	if raw := r.get("total_watch_hours"); raw != "" {
+		if item.TotalWatchHours, err = strconv.ParseFloat(raw, 64); err != nil {
+			return item, fmt.Errorf("total_watch_hours: %w", err)
+		}
+	}
+	item.TopUsers, item.TopUsersTotalHours = parseTopUsers(r.get("top_users"))
+	if raw := r.get("score"); raw != "" {
+		if item.Score, err = strconv.ParseFloat(raw, 64); err != nil {
+			return item, fmt.Errorf("score: %w", err)
+		}
+	}
+
+	// A blank cell is more likely cleared by accident than a decision to
+	// delete, so unlike JSON reports it keeps the item.
+	action := r.get("decision", "action")
+	if action == "" {
+		item.Action = ActionKeep
+		return item, nil
+	}
+	item.Action, err = NormalizeAction(action)
+	if err != nil {
+		return item, err
+	}
+	return item, nil
+}
+
+func parseDisks(value string) ([]DiskBytes, error) {
+	var out []DiskBytes
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		disk, raw, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected disk:bytes, got %q", part)
+		}
+		bytes, err := parseBytes(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, DiskBytes{Disk: strings.TrimSpace(disk), Bytes: bytes})
+	}
+	return out, nil
+}
+
+func parseOptionalInt(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func parseBytes(value string) (int64, error) {
+	if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return parsed, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(parsed), nil
+}
+
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			utc := t.UTC()
+			return &utc, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognized time %q", value)
+}
+
+func parseTopUsers(value string) ([]UserWatch, float64) {
+	if value == "" {
+		return nil, 0
+	}
+	var users []UserWatch
+	var total float64
+	for _, match := range topUserPattern.FindAllStringSubmatch(value, -1) {
+		hours, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			continue
+		}
+		if match[1] == "total" {
+			total = hours
+			continue
+		}
+		users = append(users, UserWatch{User: match[1], Hours: hours})
+	}
+	return users, total
+}
@@ -10,29 +10,139 @@ import (
 	"time"
 )
 
+const (
+	ActionDelete         = "delete"
+	ActionDeleteFiles    = "delete_files"
+	ActionKeepLastSeason = "keep_last_season"
+	ActionKeep           = "keep"
+	ActionIgnore         = "ignore"
+	ActionQuarantine     = "quarantine"
+)
+
+const ProtectionInProgress = "protected_in_progress"
+
 type Report struct {
-	GeneratedAt time.Time `json:"generated_at"`
-	Items       []Item    `json:"items"`
+	GeneratedAt        time.Time `json:"generated_at"`
+	ReclaimTargetBytes int64     `json:"reclaim_target_bytes,omitempty"`
+	Items              []Item    `json:"items"`
 }
 
 type Item struct {
-	Type               string      `json:"type"`
-	Title              string      `json:"title"`
-	RadarrID           *int        `json:"radarr_id,omitempty"`
-	SonarrID           *int        `json:"sonarr_id,omitempty"`
-	TMDBID             *int        `json:"tmdb_id,omitempty"`
-	TVDBID             *int        `json:"tvdb_id,omitempty"`
-	IMDBID             string      `json:"imdb_id,omitempty"`
-	Path               string      `json:"path"`
-	SizeBytes          int64       `json:"size_bytes"`
-	AddedAt            *time.Time  `json:"added_at,omitempty"`
-	FirstActivityAt    *time.Time  `json:"first_activity_at,omitempty"`
-	LastActivityAt     *time.Time  `json:"last_activity_at,omitempty"`
-	TopUsers           []UserWatch `json:"top_users,omitempty"`
-	TopUsersTotalHours float64     `json:"top_users_total_hours,omitempty"`
-	TotalWatchHours    float64     `json:"total_watch_hours,omitempty"`
-	SeriesStatus       string      `json:"series_status,omitempty"`
-	Reason             string      `json:"reason"`
+	Type               string        `json:"type"`
+	Title              string        `json:"title"`
+	Instance           string        `json:"instance,omitempty"`
+	AlsoIn             []string      `json:"also_in,omitempty"`
+	RadarrID           *int          `json:"radarr_id,omitempty"`
+	SonarrID           *int          `json:"sonarr_id,omitempty"`
+	TMDBID             *int          `json:"tmdb_id,omitempty"`
+	TVDBID             *int          `json:"tvdb_id,omitempty"`
+	IMDBID             string        `json:"imdb_id,omitempty"`
+	Path               string        `json:"path"`
+	LocalPath          string        `json:"local_path,omitempty"`
+	SizeBytes          int64         `json:"size_bytes"`
+	ReclaimableBytes   *int64        `json:"reclaimable_bytes,omitempty"`
+	AddedAt            *time.Time    `json:"added_at,omitempty"`
+	FirstActivityAt    *time.Time    `json:"first_activity_at,omitempty"`
+	LastActivityAt     *time.Time    `json:"last_activity_at,omitempty"`
+	TopUsers           []UserWatch   `json:"top_users,omitempty"`
+	TopUsersTotalHours float64       `json:"top_users_total_hours,omitempty"`
+	TotalWatchHours    float64       `json:"total_watch_hours,omitempty"`
+	SeriesStatus       string        `json:"series_status,omitempty"`
+	SeasonNumber       *int          `json:"season_number,omitempty"`
+	Reason             string        `json:"reason"`
+	MatchedRules       []string      `json:"matched_rules,omitempty"`
+	Protection         string        `json:"protection,omitempty"`
+	RequestedBy        string        `json:"requested_by,omitempty"`
+	RequestedAt        *time.Time    `json:"requested_at,omitempty"`
+	Torrents           []TorrentInfo `json:"torrents,omitempty"`
+	Disks              []DiskBytes   `json:"disks,omitempty"`
+	Viewers            int           `json:"viewers,omitempty"`
+	Score              float64       `json:"score"`
+	ScoreBreakdown     []ScoreFactor `json:"score_breakdown,omitempty"`
+	CumulativeBytes    int64         `json:"cumulative_bytes,omitempty"`
+	Action             string        `json:"action"`
+}
+
+// Key identifies an item across reports. Radarr and Sonarr IDs are only
+// unique within an instance, so the instance name is part of the key; items
+// without one belong to the default "radarr" or "sonarr" instance.
+func (i Item) Key() string {
+	switch {
+	case i.Type == "movie" && i.RadarrID != nil:
+		return fmt.Sprintf("movie:%s:%d", i.instanceOr("radarr"), *i.RadarrID)
+	case i.Type == "series" && i.SonarrID != nil:
+		return fmt.Sprintf("series:%s:%d", i.instanceOr("sonarr"), *i.SonarrID)
+	case i.Type == "season" && i.SonarrID != nil && i.SeasonNumber != nil:
+		return fmt.Sprintf("season:%s:%d:%d", i.instanceOr("sonarr"), *i.SonarrID, *i.SeasonNumber)
+	default:
+		return fmt.Sprintf("%s:%s", i.Type, i.Path)
+	}
+}
+
+// Find returns the item with the given key.
+func (r *Report) Find(key string) (Item, bool) {
+	for _, item := range r.Items {
+		if item.Key() == key {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+func (i Item) instanceOr(fallback string) string {
+	if i.Instance != "" {
+		return i.Instance
+	}
+	return fallback
+}
+
+// Reclaimable returns the bytes deleting the item is expected to free, which
+// is less than SizeBytes when files are hardlinked elsewhere or a torrent
+// keeps seeding the same data.
+func (i Item) Reclaimable() int64 {
+	if i.ReclaimableBytes != nil {
+		return *i.ReclaimableBytes
+	}
+	return i.SizeBytes
+}
+
+// TorrentInfo describes a torrent in the download client that Radarr or
+// Sonarr imported the item from.
+type TorrentInfo struct {
+	Hash           string  `json:"hash"`
+	Name           string  `json:"name,omitempty"`
+	State          string  `json:"state,omitempty"`
+	Seeding        bool    `json:"seeding"`
+	Ratio          float64 `json:"ratio"`
+	SeedingSeconds int64   `json:"seeding_seconds"`
+	SizeBytes      int64   `json:"size_bytes,omitempty"`
+	GoalMet        bool    `json:"goal_met"`
+}
+
+// DiskBytes is how much of an item lives on one Unraid array disk or pool.
+type DiskBytes struct {
+	Disk  string `json:"disk"`
+	Bytes int64  `json:"bytes"`
+}
+
+// OnDisks returns the bytes of the item stored on any of disks.
+func (i Item) OnDisks(disks []string) int64 {
+	var total int64
+	for _, d := range i.Disks {
+		for _, want := range disks {
+			if strings.EqualFold(d.Disk, want) {
+				total += d.Bytes
+			}
+		}
+	}
+	return total
+}
+
+type ScoreFactor struct {
+	Factor       string  `json:"factor"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
 }
 
 type UserWatch struct {
@@ -41,9 +151,39 @@ type UserWatch struct {
 }
 
 type Summary struct {
-	Total    int
-	ByType   map[string]int
-	ByReason map[string]int
+	Total            int
+	TotalBytes       int64
+	ReclaimableBytes int64
+	ByDisk           map[string]int64
+	ByType           map[string]int
+	ByReason         map[string]int
+	ByAction         map[string]int
+}
+
+func NormalizeAction(action string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(action))
+	normalized = strings.NewReplacer("-", "_", " ", "_").Replace(normalized)
+	switch normalized {
+	case "":
+		return ActionDelete, nil
+	case ActionDelete, ActionDeleteFiles, ActionKeepLastSeason, ActionKeep, ActionIgnore, ActionQuarantine:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("unknown action %q", action)
+	}
+}
+
+// CheckAction reports whether action can be applied to item. Keeping the
+// last season only works on series, and seasons cannot be quarantined since
+// their files cannot be re-added to Sonarr on their own.
+func CheckAction(item Item, action string) error {
+	switch {
+	case action == ActionKeepLastSeason && item.Type != "series":
+		return fmt.Errorf("%s is only valid for series", ActionKeepLastSeason)
+	case action == ActionQuarantine && item.Type == "season":
+		return fmt.Errorf("%s is not supported for seasons; use %s or %s", ActionQuarantine, ActionDelete, ActionKeep)
+	}
+	return nil
 }
 
 func WriteJSON(path string, report *Report) error {
@@ -70,12 +210,21 @@ func WriteCSV(path string, report *Report) error {
 	if err := writer.Write([]string{
 		"type",
 		"title",
+		"instance",
+		"also_in",
 		"radarr_id",
 		"sonarr_id",
+		"tmdb_id",
+		"tvdb_id",
+		"imdb_id",
 		"series_status",
+		"season_number",
 		"path",
+		"local_path",
 		"size_bytes",
 		"size_gib",
+		"reclaimable_bytes",
+		"reclaimable_gib",
 		"added_at",
 		"first_activity_at",
 		"last_activity_at",
@@ -85,6 +234,18 @@ func WriteCSV(path string, report *Report) error {
 		"top_users_hours_total",
 		"total_watch_hours",
 		"reason",
+		"matched_rules",
+		"protection",
+		"requested_by",
+		"requested_at",
+		"torrent_hashes",
+		"torrent_status",
+		"disks",
+		"score",
+		"score_breakdown",
+		"cumulative_gib",
+		"action",
+		"generated_at",
 	}); err != nil {
 		return fmt.Errorf("write csv header: %w", err)
 	}
@@ -93,12 +254,21 @@ func WriteCSV(path string, report *Report) error {
 		row := []string{
 			item.Type,
 			item.Title,
+			item.Instance,
+			strings.Join(item.AlsoIn, ";"),
 			formatOptionalInt(item.RadarrID),
 			formatOptionalInt(item.SonarrID),
+			formatOptionalInt(item.TMDBID),
+			formatOptionalInt(item.TVDBID),
+			item.IMDBID,
 			item.SeriesStatus,
+			formatOptionalInt(item.SeasonNumber),
 			item.Path,
+			item.LocalPath,
 			fmt.Sprintf("%d", item.SizeBytes),
 			formatSizeGiB(item.SizeBytes),
+			formatOptionalInt64(item.ReclaimableBytes),
+			formatOptionalGiB(item.ReclaimableBytes),
 			formatOptionalTime(item.AddedAt),
 			formatOptionalTime(item.FirstActivityAt),
 			formatOptionalTime(item.LastActivityAt),
@@ -108,6 +278,18 @@ func WriteCSV(path string, report *Report) error {
 			formatHours(item.TopUsersTotalHours),
 			formatHours(item.TotalWatchHours),
 			item.Reason,
+			strings.Join(item.MatchedRules, ";"),
+			item.Protection,
+			item.RequestedBy,
+			formatOptionalTime(item.RequestedAt),
+			formatTorrentHashes(item.Torrents),
+			formatTorrentStatus(item.Torrents),
+			formatDisks(item.Disks),
+			fmt.Sprintf("%.2f", item.Score),
+			FormatScoreBreakdown(item.ScoreBreakdown),
+			formatCumulative(item.CumulativeBytes),
+			item.Action,
+			report.GeneratedAt.UTC().Format(time.RFC3339),
 		}
 		if err := writer.Write(row); err != nil {
 			return fmt.Errorf("write csv row: %w", err)
@@ -137,16 +319,28 @@ func ReadJSON(path string) (*Report, error) {
 func Summarize(report *Report) Summary {
 	out := Summary{
 		Total:    len(report.Items),
+		ByDisk:   map[string]int64{},
 		ByType:   map[string]int{},
 		ByReason: map[string]int{},
+		ByAction: map[string]int{},
 	}
 	for _, item := range report.Items {
+		out.TotalBytes += item.SizeBytes
+		out.ReclaimableBytes += item.Reclaimable()
+		for _, d := range item.Disks {
+			out.ByDisk[d.Disk] += d.Bytes
+		}
 		if item.Type != "" {
 			out.ByType[item.Type]++
 		}
 		if item.Reason != "" {
 			out.ByReason[item.Reason]++
 		}
+		if action, err := NormalizeAction(item.Action); err == nil {
+			out.ByAction[action]++
+		} else {
+			out.ByAction[item.Action]++
+		}
 	}
 	return out
 }
@@ -157,15 +351,17 @@ func PrintTable(report *Report) {
 		return
 	}
 	w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
-	fmt.Fprintln(w, "TYPE\tTITLE\tSTATUS\tSIZE(GiB)\tADDED\tFIRST_ACTIVITY\tLAST_ACTIVITY\tGAP_DAYS\tINACTIVITY_DAYS\tWATCH_HOURS\tTOP_USERS\tREASON\tPATH")
+	fmt.Fprintln(w, "TYPE\tINSTANCE\tTITLE\tSTATUS\tSIZE(GiB)\tRECLAIM(GiB)\tADDED\tFIRST_ACTIVITY\tLAST_ACTIVITY\tGAP_DAYS\tINACTIVITY_DAYS\tWATCH_HOURS\tTOP_USERS\tREASON\tACTION\tPATH")
 	for _, item := range report.Items {
 		fmt.Fprintf(
 			w,
-			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			item.Type,
+			formatInstance(item),
 			item.Title,
 			item.SeriesStatus,
 			formatSizeGiB(item.SizeBytes),
+			formatSizeGiB(item.Reclaimable()),
 			formatOptionalTime(item.AddedAt),
 			formatOptionalTime(item.FirstActivityAt),
 			formatOptionalTime(item.LastActivityAt),
@@ -174,12 +370,22 @@ func PrintTable(report *Report) {
 			formatHours(item.TotalWatchHours),
 			formatTopUsers(item.TopUsers, item.TopUsersTotalHours),
 			item.Reason,
+			item.Action,
 			item.Path,
 		)
 	}
 	_ = w.Flush()
 }
 
+// formatInstance shows the instance, marking items whose title is also in
+// other instances, e.g. "radarr (+radarr-4k)".
+func formatInstance(item Item) string {
+	if len(item.AlsoIn) == 0 {
+		return item.Instance
+	}
+	return fmt.Sprintf("%s (+%s)", item.Instance, strings.Join(item.AlsoIn, ",+"))
+}
+
 func formatOptionalInt(val *int) string {
 	if val == nil {
 		return ""
@@ -187,6 +393,50 @@ func formatOptionalInt(val *int) string {
 	return fmt.Sprintf("%d", *val)
 }
 
+func formatOptionalInt64(val *int64) string {
+	if val == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *val)
+}
+
+func formatOptionalGiB(val *int64) string {
+	if val == nil {
+		return ""
+	}
+	return formatSizeGiB(*val)
+}
+
+func formatTorrentHashes(torrents []TorrentInfo) string {
+	hashes := make([]string, 0, len(torrents))
+	for _, t := range torrents {
+		hashes = append(hashes, t.Hash)
+	}
+	return strings.Join(hashes, ";")
+}
+
+// formatTorrentStatus renders each torrent as "state ratio/seed days", with
+// a trailing "*" once the seeding goal is met.
+func formatTorrentStatus(torrents []TorrentInfo) string {
+	parts := make([]string, 0, len(torrents))
+	for _, t := range torrents {
+		part := fmt.Sprintf("%s %.2f/%.1fd", t.State, t.Ratio, float64(t.SeedingSeconds)/86400)
+		if t.GoalMet {
+			part += "*"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ";")
+}
+
+func formatDisks(disks []DiskBytes) string {
+	parts := make([]string, 0, len(disks))
+	for _, d := range disks {
+		parts = append(parts, fmt.Sprintf("%s:%d", d.Disk, d.Bytes))
+	}
+	return strings.Join(parts, ";")
+}
+
 func formatOptionalTime(val *time.Time) string {
 	if val == nil {
 		return ""
@@ -245,6 +495,24 @@ func formatTopUsers(users []UserWatch, total float64) string {
 	return strings.Join(parts, " ")
 }
 
+func FormatScoreBreakdown(factors []ScoreFactor) string {
+	if len(factors) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(factors))
+	for _, factor := range factors {
+		parts = append(parts, fmt.Sprintf("%s=%.2f[%.1f*%.3g]", factor.Factor, factor.Contribution, factor.Value, factor.Weight))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatCumulative(bytes int64) string {
+	if bytes <= 0 {
+		return ""
+	}
+	return formatSizeGiB(bytes)
+}
+
 func formatHours(hours float64) string {
 	if hours <= 0 {
 		return ""
@@ -0,0 +1,228 @@
+package scan
+
+import (
+	"fmt"
+	"time"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/pathmap"
+	"go-unraid-clean/internal/report"
+)
+
+const (
+	skipNoFiles     = "no files on disk"
+	skipException   = "matches an exception"
+	skipNotEnded    = "series status is not ended"
+	skipRulesNotMet = "does not match cleanup rules"
+)
+
+func skipExcludedBy(rule string) string {
+	return fmt.Sprintf("excluded by custom rule %s", rule)
+}
+
+type evaluator struct {
+	rules       config.Rules
+	overseerr   config.Overseerr
+	activity    *activityIndex
+	watch       *watchIndex
+	seasons     *seasonIndex
+	protected   *activityIndex
+	requests    *requestIndex
+	exceptions  *exceptionSet
+	copies      *copyIndex
+	paths       *pathmap.Set
+	now         time.Time
+	cutoffWatch time.Duration
+	cutoffNever time.Duration
+	custom      []customRule
+	ruleErrors  map[string]bool
+}
+
+func newEvaluator(cfg config.Config, data activityData, now time.Time) (*evaluator, error) {
+	custom, err := compileCustomRules(cfg.Rules.Custom)
+	if err != nil {
+		return nil, err
+	}
+	activity, watch, seasons := buildIndexes(data.entries, cfg.Rules.ActivityMinPercent)
+	var protected *activityIndex
+	if cfg.Rules.InProgress.Enabled {
+		window := time.Duration(cfg.Rules.InProgress.WindowDays) * 24 * time.Hour
+		protected = buildProtectedIndex(data.entries, data.live, now, window)
+	}
+	return &evaluator{
+		rules:       cfg.Rules,
+		overseerr:   cfg.Overseerr,
+		activity:    activity,
+		watch:       watch,
+		seasons:     seasons,
+		protected:   protected,
+		requests:    newRequestIndex(data.requests),
+		exceptions:  newExceptionSet(cfg),
+		paths:       pathmap.FromConfig(cfg),
+		now:         now,
+		cutoffWatch: time.Duration(cfg.Rules.InactivityDaysAfterWatch) * 24 * time.Hour,
+		cutoffNever: time.Duration(cfg.Rules.NeverWatchedDaysSinceAdded) * 24 * time.Hour,
+		custom:      custom,
+		ruleErrors:  map[string]bool{},
+	}, nil
+}
+
+func (e *evaluator) movieItem(instance string, movie clients.RadarrMovie) (report.Item, string) {
+	if !movie.HasFile || movie.SizeOnDisk == 0 {
+		return report.Item{}, skipNoFiles
+	}
+	if e.exceptions.isMovieException(instance, movie.ID, movie.TMDBID, movie.IMDBID, movie.Title, movie.Path, e.paths.Radarr(instance).Map(movie.Path)) {
+		return report.Item{}, skipException
+	}
+	item, skip := e.decide(e.movieCandidate(instance, movie), movieFacts(movie))
+	if skip == "" {
+		watchers := e.watch.movieUsers(movie.TMDBID, movie.IMDBID, normalizeTitleYear(movie.Title, movie.Year))
+		if hold := e.requestHold(item, watchers); hold != "" {
+			return report.Item{}, hold
+		}
+	}
+	return e.protect(item, skip, e.movieProtected(movie))
+}
+
+func (e *evaluator) movieProtected(movie clients.RadarrMovie) bool {
+	if e.protected == nil {
+		return false
+	}
+	return e.protected.movieLastActivity(movie.TMDBID, movie.IMDBID, normalizeTitleYear(movie.Title, movie.Year)) != nil
+}
+
+func (e *evaluator) seriesWatchers(show clients.SonarrSeries) map[string]int64 {
+	return e.watch.seriesUsers(show.TVDBID, show.IMDBID, normalizeTitle(show.Title))
+}
+
+func (e *evaluator) seriesProtected(show clients.SonarrSeries) bool {
+	if e.protected == nil {
+		return false
+	}
+	return e.protected.seriesLastActivity(show.TVDBID, show.IMDBID, normalizeTitle(show.Title)) != nil
+}
+
+// movieCandidate builds the report item for a movie regardless of whether it
+// meets the cleanup rules; Reason is empty when no built-in rule matches.
+func (e *evaluator) movieCandidate(instance string, movie clients.RadarrMovie) report.Item {
+	titleKey := normalizeTitleYear(movie.Title, movie.Year)
+	firstActivity := e.activity.movieFirstActivity(movie.TMDBID, movie.IMDBID, titleKey)
+	lastActivity := e.activity.movieLastActivity(movie.TMDBID, movie.IMDBID, titleKey)
+	topUsers := e.watch.movieTopUsers(movie.TMDBID, movie.IMDBID, titleKey, 2)
+	topUsersOut, topTotal := toReportUsers(topUsers)
+	totalWatchHours := float64(e.watch.movieTotalSeconds(movie.TMDBID, movie.IMDBID, titleKey)) / 3600
+	addedAt := parseTime(movie.Added)
+
+	reason := evaluate(e.now, lastActivity, addedAt, e.cutoffWatch, e.cutoffNever, totalWatchHours, e.rules)
+
+	id := movie.ID
+	var tmdbPtr *int
+	if movie.TMDBID > 0 {
+		tmdb := movie.TMDBID
+		tmdbPtr = &tmdb
+	}
+	item := report.Item{
+		Type:               "movie",
+		Title:              fmt.Sprintf("%s (%d)", movie.Title, movie.Year),
+		Instance:           instance,
+		AlsoIn:             e.copies.movieAlsoIn(movie.TMDBID, instance),
+		RadarrID:           &id,
+		TMDBID:             tmdbPtr,
+		IMDBID:             movie.IMDBID,
+		Path:               movie.Path,
+		LocalPath:          e.paths.Radarr(instance).Map(movie.Path),
+		SizeBytes:          movie.SizeOnDisk,
+		AddedAt:            addedAt,
+		FirstActivityAt:    firstActivity,
+		LastActivityAt:     lastActivity,
+		TopUsers:           topUsersOut,
+		TopUsersTotalHours: topTotal,
+		TotalWatchHours:    totalWatchHours,
+		Viewers:            e.watch.movieViewerCount(movie.TMDBID, movie.IMDBID, titleKey),
+		Reason:             reason,
+		Action:             report.ActionDelete,
+	}
+	e.applyRequest(&item)
+	return item
+}
+
+func (e *evaluator) seriesItem(instance string, show clients.SonarrSeries) (report.Item, string) {
+	if show.Statistics.SizeOnDisk == 0 {
+		return report.Item{}, skipNoFiles
+	}
+	if e.exceptions.isSeriesException(instance, show.ID, show.TVDBID, show.IMDBID, show.Title, show.Path, e.paths.Sonarr(instance).Map(show.Path)) {
+		return report.Item{}, skipException
+	}
+	if e.rules.SeriesEndedOnly && !isEndedStatus(show.Status) {
+		return report.Item{}, skipNotEnded
+	}
+	item, skip := e.decide(e.seriesCandidate(instance, show), seriesFacts(show))
+	if skip == "" {
+		if hold := e.requestHold(item, e.seriesWatchers(show)); hold != "" {
+			return report.Item{}, hold
+		}
+	}
+	return e.protect(item, skip, e.seriesProtected(show))
+}
+
+func (e *evaluator) seriesCandidate(instance string, show clients.SonarrSeries) report.Item {
+	titleKey := normalizeTitle(show.Title)
+	firstActivity := e.activity.seriesFirstActivity(show.TVDBID, show.IMDBID, titleKey)
+	lastActivity := e.activity.seriesLastActivity(show.TVDBID, show.IMDBID, titleKey)
+	topUsers := e.watch.seriesTopUsers(show.TVDBID, show.IMDBID, titleKey, 2)
+	topUsersOut, topTotal := toReportUsers(topUsers)
+	totalWatchHours := float64(e.watch.seriesTotalSeconds(show.TVDBID, show.IMDBID, titleKey)) / 3600
+	addedAt := parseTime(show.Added)
+
+	reason := evaluate(e.now, lastActivity, addedAt, e.cutoffWatch, e.cutoffNever, totalWatchHours, e.rules)
+
+	id := show.ID
+	var tvdbPtr *int
+	if show.TVDBID > 0 {
+		tvdb := show.TVDBID
+		tvdbPtr = &tvdb
+	}
+	item := report.Item{
+		Type:               "series",
+		Title:              show.Title,
+		Instance:           instance,
+		AlsoIn:             e.copies.seriesAlsoIn(show.TVDBID, instance),
+		SonarrID:           &id,
+		TVDBID:             tvdbPtr,
+		IMDBID:             show.IMDBID,
+		Path:               show.Path,
+		LocalPath:          e.paths.Sonarr(instance).Map(show.Path),
+		SizeBytes:          show.Statistics.SizeOnDisk,
+		AddedAt:            addedAt,
+		FirstActivityAt:    firstActivity,
+		LastActivityAt:     lastActivity,
+		TopUsers:           topUsersOut,
+		TopUsersTotalHours: topTotal,
+		TotalWatchHours:    totalWatchHours,
+		Viewers:            e.watch.seriesViewerCount(show.TVDBID, show.IMDBID, titleKey),
+		SeriesStatus:       show.Status,
+		Reason:             reason,
+		Action:             report.ActionDelete,
+	}
+	e.applyRequest(&item)
+	return item
+}
+
+// decide scores a candidate and applies custom rules. Flag rules can put an
+// item in the report on their own; exclude rules always keep it out.
+func (e *evaluator) decide(item report.Item, facts itemFacts) (report.Item, string) {
+	item.Score, item.ScoreBreakdown = scoreItem(item, e.now, e.rules.Scoring)
+	matched, excludedBy := e.matchRules(item, facts)
+	if excludedBy != "" {
+		return report.Item{}, skipExcludedBy(excludedBy)
+	}
+	item.MatchedRules = matched
+	if item.Reason == "" {
+		if len(matched) == 0 {
+			return report.Item{}, skipRulesNotMet
+		}
+		item.Reason = reasonCustomRule
+	}
+	return item, ""
+}
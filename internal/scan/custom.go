@@ -0,0 +1,173 @@
+package scan
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/expr"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+const reasonCustomRule = "custom_rule"
+
+// RuleFields lists the item fields available to custom rule expressions.
+var RuleFields = []string{
+	"type",
+	"title",
+	"instance",
+	"also_in",
+	"year",
+	"path",
+	"local_path",
+	"size_bytes",
+	"size_gib",
+	"added_days",
+	"inactivity_days",
+	"never_watched",
+	"watch_hours",
+	"viewers",
+	"status",
+	"season",
+	"series_type",
+	"network",
+	"genres",
+	"quality",
+	"resolution",
+	"reason",
+	"score",
+	"requested_by",
+	"requested_days",
+}
+
+type customRule struct {
+	name   string
+	effect string
+	prog   *expr.Program
+}
+
+// itemFacts carries library metadata that feeds rule expressions but is not
+// part of the report.
+type itemFacts struct {
+	year       int
+	genres     []string
+	seriesType string
+	network    string
+	quality    string
+	resolution int
+}
+
+func compileCustomRules(rules []config.CustomRule) ([]customRule, error) {
+	out := make([]customRule, 0, len(rules))
+	for _, rule := range rules {
+		prog, err := expr.Compile(rule.When, RuleFields)
+		if err != nil {
+			return nil, fmt.Errorf("rules: custom rule %q: %w", rule.Name, err)
+		}
+		effect := rule.Effect
+		if effect == "" {
+			effect = config.RuleEffectFlag
+		}
+		out = append(out, customRule{name: rule.Name, effect: effect, prog: prog})
+	}
+	return out, nil
+}
+
+func movieFacts(movie clients.RadarrMovie) itemFacts {
+	facts := itemFacts{year: movie.Year, genres: movie.Genres}
+	if movie.MovieFile != nil {
+		facts.quality = movie.MovieFile.Quality.Quality.Name
+		facts.resolution = movie.MovieFile.Quality.Quality.Resolution
+	}
+	return facts
+}
+
+func seriesFacts(show clients.SonarrSeries) itemFacts {
+	return itemFacts{
+		year:       show.Year,
+		genres:     show.Genres,
+		seriesType: show.SeriesType,
+		network:    show.Network,
+	}
+}
+
+func ruleEnv(item report.Item, facts itemFacts, now time.Time) map[string]any {
+	addedDays := 0.0
+	if item.AddedAt != nil {
+		addedDays = now.Sub(*item.AddedAt).Hours() / 24
+	}
+	season := 0
+	if item.SeasonNumber != nil {
+		season = *item.SeasonNumber
+	}
+	requestedDays := -1.0
+	if item.RequestedAt != nil {
+		requestedDays = now.Sub(*item.RequestedAt).Hours() / 24
+	}
+	genres := facts.genres
+	if genres == nil {
+		genres = []string{}
+	}
+	alsoIn := item.AlsoIn
+	if alsoIn == nil {
+		alsoIn = []string{}
+	}
+	return map[string]any{
+		"type":            item.Type,
+		"title":           item.Title,
+		"instance":        item.Instance,
+		"also_in":         alsoIn,
+		"year":            facts.year,
+		"path":            item.Path,
+		"local_path":      item.LocalPath,
+		"size_bytes":      item.SizeBytes,
+		"size_gib":        float64(item.SizeBytes) / (1024 * 1024 * 1024),
+		"added_days":      addedDays,
+		"inactivity_days": inactivityDays(item, now),
+		"never_watched":   item.LastActivityAt == nil,
+		"watch_hours":     item.TotalWatchHours,
+		"viewers":         item.Viewers,
+		"status":          strings.ToLower(item.SeriesStatus),
+		"season":          season,
+		"series_type":     strings.ToLower(facts.seriesType),
+		"network":         facts.network,
+		"genres":          genres,
+		"quality":         facts.quality,
+		"resolution":      facts.resolution,
+		"reason":          item.Reason,
+		"score":           item.Score,
+		"requested_by":    item.RequestedBy,
+		"requested_days":  requestedDays,
+	}
+}
+
+// matchRules returns the names of matching flag rules, or the name of the
+// first matching exclude rule.
+func (e *evaluator) matchRules(item report.Item, facts itemFacts) ([]string, string) {
+	if len(e.custom) == 0 {
+		return nil, ""
+	}
+	env := ruleEnv(item, facts, e.now)
+	var matched []string
+	for _, rule := range e.custom {
+		ok, err := rule.prog.Match(env)
+		if err != nil {
+			if !e.ruleErrors[rule.name] {
+				e.ruleErrors[rule.name] = true
+				logging.L().Warn().Err(err).Str("rule", rule.name).Str("title", item.Title).Msg("Custom rule failed to evaluate")
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if rule.effect == config.RuleEffectExclude {
+			return nil, rule.name
+		}
+		matched = append(matched, rule.name)
+	}
+	return matched, ""
+}
@@ -21,7 +21,7 @@ type exceptionIndex struct {
 	seriesPaths     []string
 }
 
-func newExceptionIndex(cfg config.Config) *exceptionIndex {
+func newExceptionIndex(movies config.MovieExceptions, series config.SeriesExceptions) *exceptionIndex {
 	idx := &exceptionIndex{
 		movieRadarrIDs:  map[int]struct{}{},
 		movieTMDBIDs:    map[int]struct{}{},
@@ -35,42 +35,91 @@ func newExceptionIndex(cfg config.Config) *exceptionIndex {
 		seriesPaths:     []string{},
 	}
 
-	for _, id := range cfg.Exceptions.Movies.RadarrIDs {
+	for _, id := range movies.RadarrIDs {
 		idx.movieRadarrIDs[id] = struct{}{}
 	}
-	for _, id := range cfg.Exceptions.Movies.TMDBIDs {
+	for _, id := range movies.TMDBIDs {
 		idx.movieTMDBIDs[id] = struct{}{}
 	}
-	for _, id := range cfg.Exceptions.Movies.IMDBIDs {
+	for _, id := range movies.IMDBIDs {
 		idx.movieIMDBIDs[strings.ToLower(id)] = struct{}{}
 	}
-	for _, title := range cfg.Exceptions.Movies.Titles {
+	for _, title := range movies.Titles {
 		idx.movieTitles[normalizeTitle(title)] = struct{}{}
 	}
-	for _, prefix := range cfg.Exceptions.Movies.PathPrefixes {
+	for _, prefix := range movies.PathPrefixes {
 		idx.moviePaths = append(idx.moviePaths, filepath.Clean(prefix))
 	}
 
-	for _, id := range cfg.Exceptions.Series.SonarrIDs {
+	for _, id := range series.SonarrIDs {
 		idx.seriesSonarrIDs[id] = struct{}{}
 	}
-	for _, id := range cfg.Exceptions.Series.TVDBIDs {
+	for _, id := range series.TVDBIDs {
 		idx.seriesTVDBIDs[id] = struct{}{}
 	}
-	for _, id := range cfg.Exceptions.Series.IMDBIDs {
+	for _, id := range series.IMDBIDs {
 		idx.seriesIMDBIDs[strings.ToLower(id)] = struct{}{}
 	}
-	for _, title := range cfg.Exceptions.Series.Titles {
+	for _, title := range series.Titles {
 		idx.seriesTitles[normalizeTitle(title)] = struct{}{}
 	}
-	for _, prefix := range cfg.Exceptions.Series.PathPrefixes {
+	for _, prefix := range series.PathPrefixes {
 		idx.seriesPaths = append(idx.seriesPaths, filepath.Clean(prefix))
 	}
 
 	return idx
 }
 
-func (e *exceptionIndex) isMovieException(radarrID int, tmdbID int, imdbID string, title string, path string) bool {
+// exceptionSet combines the exceptions for every instance with those scoped
+// to one instance. Global radarr_ids and sonarr_ids only identify items in the
+// first instance, since IDs are not shared between instances.
+type exceptionSet struct {
+	global      *exceptionIndex
+	instances   map[string]*exceptionIndex
+	firstRadarr string
+	firstSonarr string
+}
+
+func newExceptionSet(cfg config.Config) *exceptionSet {
+	set := &exceptionSet{
+		global:      newExceptionIndex(cfg.Exceptions.Movies, cfg.Exceptions.Series),
+		instances:   map[string]*exceptionIndex{},
+		firstRadarr: cfg.Radarr.First(),
+		firstSonarr: cfg.Sonarr.First(),
+	}
+	for name, scoped := range cfg.Exceptions.Instances {
+		set.instances[name] = newExceptionIndex(scoped.Movies, scoped.Series)
+	}
+	return set
+}
+
+func (s *exceptionSet) isMovieException(instance string, radarrID int, tmdbID int, imdbID string, title string, paths ...string) bool {
+	globalID := radarrID
+	if instance != s.firstRadarr {
+		globalID = 0
+	}
+	if s.global.isMovieException(globalID, tmdbID, imdbID, title, paths...) {
+		return true
+	}
+	scoped, ok := s.instances[instance]
+	return ok && scoped.isMovieException(radarrID, tmdbID, imdbID, title, paths...)
+}
+
+func (s *exceptionSet) isSeriesException(instance string, sonarrID int, tvdbID int, imdbID string, title string, paths ...string) bool {
+	globalID := sonarrID
+	if instance != s.firstSonarr {
+		globalID = 0
+	}
+	if s.global.isSeriesException(globalID, tvdbID, imdbID, title, paths...) {
+		return true
+	}
+	scoped, ok := s.instances[instance]
+	return ok && scoped.isSeriesException(sonarrID, tvdbID, imdbID, title, paths...)
+}
+
+// isMovieException matches path prefixes against every given path, so
+// prefixes may be written as Radarr reports them or as host paths.
+func (e *exceptionIndex) isMovieException(radarrID int, tmdbID int, imdbID string, title string, paths ...string) bool {
 	if radarrID > 0 {
 		if _, ok := e.movieRadarrIDs[radarrID]; ok {
 			return true
@@ -91,10 +140,10 @@ func (e *exceptionIndex) isMovieException(radarrID int, tmdbID int, imdbID strin
 			return true
 		}
 	}
-	return hasPathPrefix(path, e.moviePaths)
+	return hasPathPrefix(paths, e.moviePaths)
 }
 
-func (e *exceptionIndex) isSeriesException(sonarrID int, tvdbID int, imdbID string, title string, path string) bool {
+func (e *exceptionIndex) isSeriesException(sonarrID int, tvdbID int, imdbID string, title string, paths ...string) bool {
 	if sonarrID > 0 {
 		if _, ok := e.seriesSonarrIDs[sonarrID]; ok {
 			return true
@@ -115,17 +164,22 @@ func (e *exceptionIndex) isSeriesException(sonarrID int, tvdbID int, imdbID stri
 			return true
 		}
 	}
-	return hasPathPrefix(path, e.seriesPaths)
+	return hasPathPrefix(paths, e.seriesPaths)
 }
 
-func hasPathPrefix(path string, prefixes []string) bool {
-	if path == "" || len(prefixes) == 0 {
+func hasPathPrefix(paths []string, prefixes []string) bool {
+	if len(prefixes) == 0 {
 		return false
 	}
-	cleaned := filepath.Clean(path)
-	for _, prefix := range prefixes {
-		if strings.HasPrefix(cleaned, prefix) {
-			return true
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		cleaned := filepath.Clean(path)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(cleaned, prefix) {
+				return true
+			}
 		}
 	}
 	return false
@@ -0,0 +1,121 @@
+package scan
+
+import (
+	"context"
+	"time"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/logging"
+)
+
+// activityData is everything fetched from activity and request sources that
+// the evaluator needs besides the library itself.
+type activityData struct {
+	entries  []historyEntry
+	live     []historyEntry
+	requests []clients.OverseerrRequest
+}
+
+func fetchActivity(ctx context.Context, cfg config.Config, now time.Time) (activityData, error) {
+	var data activityData
+	var err error
+	data.entries, err = fetchHistory(ctx, cfg)
+	if err != nil {
+		return activityData{}, err
+	}
+	if cfg.Rules.InProgress.Enabled {
+		data.live, err = fetchLiveActivity(ctx, cfg, now)
+		if err != nil {
+			return activityData{}, err
+		}
+	}
+	if cfg.Overseerr.BaseURL != "" {
+		data.requests, err = fetchRequests(ctx, cfg)
+		if err != nil {
+			return activityData{}, err
+		}
+	}
+	return data, nil
+}
+
+// fetchHistory loads watch history from every configured activity source and
+// normalizes it into historyEntry values. Jellyfin and Emby plays never reach
+// Tautulli, so they are appended as-is.
+func fetchHistory(ctx context.Context, cfg config.Config) ([]historyEntry, error) {
+	var tautulli, plex, other []historyEntry
+	for _, source := range cfg.ActivitySources() {
+		var err error
+		switch source {
+		case config.ActivitySourceTautulli:
+			tautulli, err = fetchTautulliHistory(ctx, cfg)
+		case config.ActivitySourcePlex:
+			plex, err = fetchPlexHistory(ctx, cfg)
+		case config.ActivitySourceJellyfin, config.ActivitySourceEmby:
+			var entries []historyEntry
+			entries, err = fetchJellyfinHistory(ctx, cfg, source)
+			other = append(other, entries...)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(mergeHistory(tautulli, plex), other...), nil
+}
+
+func fetchTautulliHistory(ctx context.Context, cfg config.Config) ([]historyEntry, error) {
+	log := logging.L()
+	tautulli, err := clients.NewTautulliClient(cfg.Tautulli.BaseURL, cfg.Tautulli.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Msg("Fetching Tautulli history")
+	raw, err := tautulli.History(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Int("count", len(raw)).Msg("Loaded Tautulli history entries")
+	entries := make([]historyEntry, 0, len(raw))
+	for _, item := range raw {
+		if entry, ok := parseHistoryEntry(item); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// mergeHistory combines Tautulli and Plex history. Plex plays are only kept
+// from before Tautulli's earliest entry so overlapping plays are not counted
+// twice; Plex last-viewed markers carry no watch time and are always kept.
+func mergeHistory(tautulli []historyEntry, plex []historyEntry) []historyEntry {
+	if len(tautulli) == 0 {
+		return plex
+	}
+	if len(plex) == 0 {
+		return tautulli
+	}
+	earliest := tautulli[0].When
+	for _, entry := range tautulli {
+		if entry.When.Before(earliest) {
+			earliest = entry.When
+		}
+	}
+	out := make([]historyEntry, 0, len(tautulli)+len(plex))
+	out = append(out, tautulli...)
+	kept := 0
+	for _, entry := range plex {
+		if entry.WatchSeconds == 0 || entry.When.Before(earliest) {
+			out = append(out, entry)
+			kept++
+		}
+	}
+	logging.L().Debug().Int("kept", kept).Time("tautulli_start", earliest).Msg("Merged Plex history older than Tautulli")
+	return out
+}
+
+func unixTime(seconds int64) (time.Time, bool) {
+	if seconds <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0).UTC(), true
+}
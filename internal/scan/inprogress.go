@@ -0,0 +1,103 @@
+package scan
+
+import (
+	"context"
+	"time"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+const skipInProgress = "in progress"
+
+// fetchLiveActivity returns in-progress entries for items being streamed now
+// (Tautulli get_activity, Plex sessions) or sitting on Plex's On Deck. Plex is
+// optional: failures there are logged and ignored.
+func fetchLiveActivity(ctx context.Context, cfg config.Config, now time.Time) ([]historyEntry, error) {
+	log := logging.L()
+	var live []historyEntry
+	for _, source := range cfg.ActivitySources() {
+		if source != config.ActivitySourceTautulli {
+			continue
+		}
+		tautulli, err := clients.NewTautulliClient(cfg.Tautulli.BaseURL, cfg.Tautulli.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		log.Info().Msg("Fetching Tautulli activity")
+		sessions, err := tautulli.Activity(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range sessions {
+			live = append(live, parseSessionEntry(session, now))
+		}
+	}
+
+	if cfg.Plex.BaseURL != "" && cfg.Plex.APIKey != "" {
+		plex, err := clients.NewPlexClient(cfg.Plex.BaseURL, cfg.Plex.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		sessions, err := plex.Sessions(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("Unable to fetch Plex sessions")
+		}
+		for _, item := range sessions {
+			if entry, ok := plexLiveEntry(item, now); ok {
+				live = append(live, entry)
+			}
+		}
+		onDeck, err := plex.OnDeck(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("Unable to fetch Plex On Deck")
+		}
+		for _, item := range onDeck {
+			when, ok := unixTime(item.LastViewedAt)
+			if !ok {
+				when = now
+			}
+			if entry, ok := plexLiveEntry(item, when); ok {
+				live = append(live, entry)
+			}
+		}
+	}
+	log.Debug().Int("count", len(live)).Msg("Loaded in-progress activity")
+	return live, nil
+}
+
+func plexLiveEntry(item clients.PlexMetadata, when time.Time) (historyEntry, bool) {
+	entry := historyEntry{Title: item.Title, Year: item.Year, When: when, InProgress: true}
+	if item.User != nil {
+		entry.User = item.User.Title
+	}
+	switch item.Type {
+	case "movie":
+		entry.MediaType = "movie"
+		applyPlexGuids(&entry, item)
+	case "episode":
+		entry.MediaType = "episode"
+		entry.GrandparentTitle = item.GrandparentTitle
+		entry.SeasonNumber = item.ParentIndex
+		entry.EpisodeNumber = item.Index
+	default:
+		return historyEntry{}, false
+	}
+	return entry, true
+}
+
+// protect applies the in-progress mode to an item that survived the rules:
+// exclude drops it, mark keeps it in the report with action keep.
+func (e *evaluator) protect(item report.Item, skip string, protected bool) (report.Item, string) {
+	if skip != "" || !protected {
+		return item, skip
+	}
+	if e.rules.InProgress.Mode == config.InProgressModeMark {
+		item.Protection = report.ProtectionInProgress
+		item.Action = report.ActionKeep
+		return item, ""
+	}
+	return report.Item{}, skipInProgress
+}
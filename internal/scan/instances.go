@@ -0,0 +1,157 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go-unraid-clean/internal/arr"
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+// instanceMovie is a Radarr movie with the name of the instance it is in.
+type instanceMovie struct {
+	instance string
+	clients.RadarrMovie
+}
+
+// instanceSeries is a Sonarr series with the name of the instance it is in.
+type instanceSeries struct {
+	instance string
+	clients.SonarrSeries
+}
+
+func fetchInstances(ctx context.Context, arrs *arr.Clients) ([]instanceMovie, []instanceSeries, error) {
+	log := logging.L()
+	var movies []instanceMovie
+	for _, radarr := range arrs.Radarr {
+		log.Info().Str("instance", radarr.Name).Msg("Fetching Radarr movies")
+		list, err := radarr.Movies(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("radarr %s: %w", radarr.Name, err)
+		}
+		log.Debug().Str("instance", radarr.Name).Int("count", len(list)).Msg("Loaded Radarr movies")
+		for _, movie := range list {
+			movies = append(movies, instanceMovie{instance: radarr.Name, RadarrMovie: movie})
+		}
+	}
+	var series []instanceSeries
+	for _, sonarr := range arrs.Sonarr {
+		log.Info().Str("instance", sonarr.Name).Msg("Fetching Sonarr series")
+		list, err := sonarr.Series(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sonarr %s: %w", sonarr.Name, err)
+		}
+		log.Debug().Str("instance", sonarr.Name).Int("count", len(list)).Msg("Loaded Sonarr series")
+		for _, show := range list {
+			series = append(series, instanceSeries{instance: sonarr.Name, SonarrSeries: show})
+		}
+	}
+	return movies, series, nil
+}
+
+// copyIndex records which instances have files for each TMDB and TVDB ID, so
+// a title kept in several instances (e.g. radarr and radarr-4k) is visible as
+// such on every copy.
+type copyIndex struct {
+	movies map[int][]string
+	series map[int][]string
+}
+
+func newCopyIndex(movies []instanceMovie, series []instanceSeries) *copyIndex {
+	idx := &copyIndex{movies: map[int][]string{}, series: map[int][]string{}}
+	for _, movie := range movies {
+		if movie.TMDBID > 0 && movie.HasFile && movie.SizeOnDisk > 0 {
+			idx.movies[movie.TMDBID] = appendInstance(idx.movies[movie.TMDBID], movie.instance)
+		}
+	}
+	for _, show := range series {
+		if show.TVDBID > 0 && show.Statistics.SizeOnDisk > 0 {
+			idx.series[show.TVDBID] = appendInstance(idx.series[show.TVDBID], show.instance)
+		}
+	}
+	return idx
+}
+
+func appendInstance(list []string, name string) []string {
+	for _, existing := range list {
+		if existing == name {
+			return list
+		}
+	}
+	return append(list, name)
+}
+
+// movieAlsoIn returns the other instances holding the same TMDB ID.
+func (c *copyIndex) movieAlsoIn(tmdbID int, instance string) []string {
+	if c == nil || tmdbID <= 0 {
+		return nil
+	}
+	return otherInstances(c.movies[tmdbID], instance)
+}
+
+// seriesAlsoIn returns the other instances holding the same TVDB ID.
+func (c *copyIndex) seriesAlsoIn(tvdbID int, instance string) []string {
+	if c == nil || tvdbID <= 0 {
+		return nil
+	}
+	return otherInstances(c.series[tvdbID], instance)
+}
+
+func otherInstances(list []string, instance string) []string {
+	var out []string
+	for _, name := range list {
+		if name != instance {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// groupCopies moves items that are copies of the same title in other
+// instances up next to the first of them, keeping the order otherwise.
+func groupCopies(items []report.Item) []report.Item {
+	groups := map[string][]int{}
+	var order []string
+	for idx, item := range items {
+		key := copyKey(item)
+		if key == "" {
+			key = fmt.Sprintf("item:%d", idx)
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], idx)
+	}
+	out := make([]report.Item, 0, len(items))
+	for _, key := range order {
+		for _, idx := range groups[key] {
+			out = append(out, items[idx])
+		}
+	}
+	return out
+}
+
+func copyKey(item report.Item) string {
+	if len(item.AlsoIn) == 0 {
+		return ""
+	}
+	switch {
+	case item.Type == "movie" && item.TMDBID != nil:
+		return fmt.Sprintf("movie:%d", *item.TMDBID)
+	case item.Type == "series" && item.TVDBID != nil:
+		return fmt.Sprintf("series:%d", *item.TVDBID)
+	case item.Type == "season" && item.TVDBID != nil && item.SeasonNumber != nil:
+		return fmt.Sprintf("season:%d:%d", *item.TVDBID, *item.SeasonNumber)
+	}
+	return ""
+}
+
+// sameCopy reports whether a and b are copies of the same title.
+func sameCopy(a, b report.Item) bool {
+	key := copyKey(a)
+	return key != "" && key == copyKey(b)
+}
@@ -0,0 +1,140 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/logging"
+)
+
+const jellyfinTicksPerSecond = 10_000_000
+
+// fetchJellyfinHistory normalizes Jellyfin/Emby activity into history
+// entries. Playback Reporting plugin rows are used as plays when the plugin is
+// installed; per-user played and resume state always marks last activity and
+// carries watch time only when the plugin is missing.
+func fetchJellyfinHistory(ctx context.Context, cfg config.Config, source string) ([]historyEntry, error) {
+	log := logging.L().With().Str("source", source).Logger()
+	var client *clients.JellyfinClient
+	var err error
+	if source == config.ActivitySourceEmby {
+		client, err = clients.NewEmbyClient(cfg.Emby.BaseURL, cfg.Emby.APIKey)
+	} else {
+		client, err = clients.NewJellyfinClient(cfg.Jellyfin.BaseURL, cfg.Jellyfin.APIKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msg("Fetching media server library")
+	libraryItems, err := client.Items(ctx, "Movie,Series,Episode")
+	if err != nil {
+		return nil, err
+	}
+	library := make(map[string]clients.JellyfinItem, len(libraryItems))
+	for _, item := range libraryItems {
+		library[item.ID] = item
+	}
+
+	var entries []historyEntry
+	plays, err := client.PlaybackActivity(ctx)
+	havePlays := err == nil
+	switch {
+	case errors.Is(err, clients.ErrNotFound):
+		log.Debug().Msg("Playback Reporting plugin not available; using played state only")
+	case err != nil:
+		return nil, err
+	default:
+		for _, play := range plays {
+			item, ok := library[play.ItemID]
+			if !ok {
+				continue
+			}
+			when := parseTime(play.Date)
+			if when == nil {
+				continue
+			}
+			if entry, ok := jellyfinEntry(item, library, play.User, *when, play.Seconds); ok {
+				entries = append(entries, entry)
+			}
+		}
+		log.Debug().Int("count", len(plays)).Msg("Loaded playback reporting rows")
+	}
+
+	users, err := client.Users(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		for _, filter := range []string{"IsPlayed", "IsResumable"} {
+			items, err := client.UserItems(ctx, user.ID, filter)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				if item.UserData == nil {
+					continue
+				}
+				when := parseTime(item.UserData.LastPlayedDate)
+				if when == nil {
+					continue
+				}
+				var seconds int64
+				if !havePlays {
+					seconds = item.RunTimeTicks / jellyfinTicksPerSecond
+					if !item.UserData.Played {
+						seconds = item.UserData.PlaybackPositionTicks / jellyfinTicksPerSecond
+					}
+				}
+				if entry, ok := jellyfinEntry(item, library, user.Name, *when, seconds); ok {
+					entry.InProgress = !item.UserData.Played && item.UserData.PlaybackPositionTicks > 0
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+	log.Debug().Int("count", len(entries)).Msg("Loaded media server history entries")
+	return entries, nil
+}
+
+func jellyfinEntry(item clients.JellyfinItem, library map[string]clients.JellyfinItem, user string, when time.Time, seconds int64) (historyEntry, bool) {
+	entry := historyEntry{User: user, When: when, WatchSeconds: seconds}
+	switch item.Type {
+	case "Movie":
+		entry.MediaType = "movie"
+		entry.Title = item.Name
+		entry.Year = item.ProductionYear
+		entry.TMDBID = atoiOrZero(providerID(item.ProviderIDs, "tmdb"))
+		entry.IMDBID = providerID(item.ProviderIDs, "imdb")
+	case "Episode":
+		series := library[item.SeriesID]
+		entry.MediaType = "episode"
+		entry.Title = item.Name
+		entry.GrandparentTitle = item.SeriesName
+		entry.SeasonNumber = item.ParentIndexNumber
+		entry.EpisodeNumber = item.IndexNumber
+		entry.TVDBID = atoiOrZero(providerID(series.ProviderIDs, "tvdb"))
+		entry.IMDBID = providerID(series.ProviderIDs, "imdb")
+	default:
+		return historyEntry{}, false
+	}
+	return entry, true
+}
+
+func providerID(ids map[string]string, name string) string {
+	for key, value := range ids {
+		if strings.EqualFold(key, name) {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func atoiOrZero(value string) int {
+	out, _ := coerceInt(value)
+	return out
+}
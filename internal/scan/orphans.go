@@ -0,0 +1,217 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go-unraid-clean/internal/arr"
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/fsinspect"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/pathmap"
+	"go-unraid-clean/internal/report"
+)
+
+const reasonOrphaned = "orphaned"
+
+type OrphanOptions struct {
+	// Action is the planned action written on every orphan; keep by default
+	// so applying an unedited report changes nothing.
+	Action string
+}
+
+type rootFolder struct {
+	service  string
+	instance string
+	remote   string
+	local    string
+}
+
+// trackedLibrary is every movie and series folder Radarr and Sonarr know
+// about (as host paths, including their parent directories) and the root
+// folders they live in. Root folders count as tracked too, so one nested in
+// another service's root is never reported as an orphan.
+type trackedLibrary struct {
+	paths map[string]bool
+	roots []rootFolder
+}
+
+// FindOrphans reports folders and files directly inside the Radarr and
+// Sonarr root folders that neither service tracks.
+func FindOrphans(ctx context.Context, cfg config.Config, opts OrphanOptions) (*report.Report, error) {
+	log := logging.L()
+	action := opts.Action
+	if action == "" {
+		action = report.ActionKeep
+	}
+	arrs, err := arr.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	lib, err := fetchTracked(ctx, arrs, pathmap.FromConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	ignore := make([]string, 0, len(cfg.Exceptions.Orphans.PathPrefixes))
+	for _, prefix := range cfg.Exceptions.Orphans.PathPrefixes {
+		ignore = append(ignore, filepath.Clean(prefix))
+	}
+
+	rep := &report.Report{GeneratedAt: time.Now().UTC(), Items: []report.Item{}}
+	for _, root := range lib.roots {
+		entries, err := os.ReadDir(root.local)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Warn().Str("service", root.service).Str("instance", root.instance).Str("root", root.remote).Str("local", root.local).Msg("Root folder not found locally; add a path mapping")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		log.Info().Str("root", root.local).Msg("Checking root folder for orphans")
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			local := filepath.Join(root.local, entry.Name())
+			if lib.paths[local] || hasPathPrefix([]string{local}, ignore) {
+				continue
+			}
+			item, err := orphanItem(root, entry, action)
+			if err != nil {
+				log.Warn().Err(err).Str("path", local).Msg("Unable to inspect orphan")
+				continue
+			}
+			rep.Items = append(rep.Items, item)
+		}
+	}
+	inspectItems(ctx, cfg, library{arr: arrs}, rep.Items)
+	sort.SliceStable(rep.Items, func(i, j int) bool {
+		return rep.Items[i].Reclaimable() > rep.Items[j].Reclaimable()
+	})
+	log.Info().Int("count", len(rep.Items)).Msg("Orphans found")
+	return rep, nil
+}
+
+func fetchTracked(ctx context.Context, arrs *arr.Clients, paths *pathmap.Set) (trackedLibrary, error) {
+	log := logging.L()
+	lib := trackedLibrary{paths: map[string]bool{}}
+	track := func(p string) {
+		for p != "" && p != "/" && p != "." && !lib.paths[p] {
+			lib.paths[p] = true
+			p = filepath.Dir(p)
+		}
+	}
+	seenRoots := map[string]bool{}
+	addRoots := func(service string, instance string, mapper *pathmap.Mapper, folders []clients.ArrRootFolder) {
+		for _, folder := range folders {
+			local := filepath.Clean(mapper.Map(folder.Path))
+			if seenRoots[local] {
+				continue
+			}
+			seenRoots[local] = true
+			track(local)
+			lib.roots = append(lib.roots, rootFolder{service: service, instance: instance, remote: folder.Path, local: local})
+		}
+	}
+
+	for _, radarr := range arrs.Radarr {
+		log.Info().Str("instance", radarr.Name).Msg("Fetching Radarr movies and root folders")
+		mapper := paths.Radarr(radarr.Name)
+		movies, err := radarr.Movies(ctx)
+		if err != nil {
+			return trackedLibrary{}, err
+		}
+		for _, movie := range movies {
+			track(filepath.Clean(mapper.Map(movie.Path)))
+		}
+		folders, err := radarr.RootFolders(ctx)
+		if err != nil {
+			return trackedLibrary{}, err
+		}
+		addRoots("radarr", radarr.Name, mapper, folders)
+	}
+
+	for _, sonarr := range arrs.Sonarr {
+		log.Info().Str("instance", sonarr.Name).Msg("Fetching Sonarr series and root folders")
+		mapper := paths.Sonarr(sonarr.Name)
+		series, err := sonarr.Series(ctx)
+		if err != nil {
+			return trackedLibrary{}, err
+		}
+		for _, show := range series {
+			track(filepath.Clean(mapper.Map(show.Path)))
+		}
+		folders, err := sonarr.RootFolders(ctx)
+		if err != nil {
+			return trackedLibrary{}, err
+		}
+		addRoots("sonarr", sonarr.Name, mapper, folders)
+	}
+	return lib, nil
+}
+
+// OrphanGuard re-checks orphans right before they are removed from disk.
+type OrphanGuard struct {
+	lib trackedLibrary
+}
+
+// NewOrphanGuard fetches the tracked library and root folders of every
+// Radarr and Sonarr instance.
+func NewOrphanGuard(ctx context.Context, arrs *arr.Clients, paths *pathmap.Set) (*OrphanGuard, error) {
+	lib, err := fetchTracked(ctx, arrs, paths)
+	if err != nil {
+		return nil, err
+	}
+	return &OrphanGuard{lib: lib}, nil
+}
+
+// Check returns an error unless local sits directly inside a known root
+// folder and no service tracks it, as FindOrphans required when it was
+// reported.
+func (g *OrphanGuard) Check(local string) error {
+	local = filepath.Clean(local)
+	if g.lib.paths[local] {
+		return fmt.Errorf("%s is tracked by radarr or sonarr", local)
+	}
+	parent := filepath.Dir(local)
+	for _, root := range g.lib.roots {
+		if root.local == parent {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not directly inside a radarr or sonarr root folder", local)
+}
+
+func orphanItem(root rootFolder, entry fs.DirEntry, action string) (report.Item, error) {
+	local := filepath.Join(root.local, entry.Name())
+	info, err := entry.Info()
+	if err != nil {
+		return report.Item{}, err
+	}
+	usage, err := fsinspect.Inspect(local)
+	if err != nil {
+		return report.Item{}, err
+	}
+	modified := info.ModTime().UTC()
+	return report.Item{
+		Type:      "orphan",
+		Title:     entry.Name(),
+		Instance:  root.instance,
+		Path:      path.Join(root.remote, entry.Name()),
+		LocalPath: local,
+		SizeBytes: usage.TotalBytes,
+		AddedAt:   &modified,
+		Reason:    reasonOrphaned,
+		Action:    action,
+	}, nil
+}
@@ -0,0 +1,164 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/logging"
+)
+
+const (
+	plexTypeMovie   = 1
+	plexTypeShow    = 2
+	plexTypeEpisode = 4
+)
+
+// fetchPlexHistory reads play history straight from Plex Media Server. Library
+// listings supply external IDs and durations for history rows, and each
+// item's lastViewedAt is added as a marker without watch time.
+func fetchPlexHistory(ctx context.Context, cfg config.Config) ([]historyEntry, error) {
+	log := logging.L()
+	plex, err := clients.NewPlexClient(cfg.Plex.BaseURL, cfg.Plex.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msg("Fetching Plex libraries")
+	sections, err := plex.Sections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	library := map[string]clients.PlexMetadata{}
+	var entries []historyEntry
+	for _, section := range sections {
+		var types []int
+		switch section.Type {
+		case "movie":
+			types = []int{plexTypeMovie}
+		case "show":
+			types = []int{plexTypeShow, plexTypeEpisode}
+		default:
+			continue
+		}
+		for _, itemType := range types {
+			items, err := plex.SectionItems(ctx, section.Key, itemType)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				library[item.RatingKey] = item
+				if entry, ok := plexViewedMarker(item); ok {
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+	log.Debug().Int("count", len(library)).Msg("Loaded Plex library items")
+
+	users := map[int]string{}
+	accounts, err := plex.Accounts(ctx)
+	if err != nil {
+		log.Debug().Err(err).Msg("Unable to list Plex accounts; using account IDs")
+	}
+	for _, account := range accounts {
+		users[account.ID] = account.Name
+	}
+
+	log.Info().Msg("Fetching Plex history")
+	history, err := plex.History(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range history {
+		if entry, ok := plexHistoryEntry(row, library, users); ok {
+			entries = append(entries, entry)
+		}
+	}
+	log.Debug().Int("count", len(history)).Msg("Loaded Plex history entries")
+	return entries, nil
+}
+
+func plexViewedMarker(item clients.PlexMetadata) (historyEntry, bool) {
+	when, ok := unixTime(item.LastViewedAt)
+	if !ok || item.ViewCount == 0 {
+		return historyEntry{}, false
+	}
+	entry := historyEntry{Title: item.Title, Year: item.Year, When: when}
+	switch item.Type {
+	case "movie":
+		entry.MediaType = "movie"
+	case "show":
+		entry.MediaType = "show"
+	default:
+		return historyEntry{}, false
+	}
+	applyPlexGuids(&entry, item)
+	return entry, true
+}
+
+func plexHistoryEntry(row clients.PlexMetadata, library map[string]clients.PlexMetadata, users map[int]string) (historyEntry, bool) {
+	when, ok := unixTime(row.ViewedAt)
+	if !ok {
+		return historyEntry{}, false
+	}
+	entry := historyEntry{
+		Title: row.Title,
+		When:  when,
+		User:  users[row.AccountID],
+	}
+	if entry.User == "" && row.AccountID > 0 {
+		entry.User = fmt.Sprintf("plex-%d", row.AccountID)
+	}
+
+	meta := library[plexRatingKey(row.RatingKey, row.Key)]
+	entry.WatchSeconds = meta.Duration / 1000
+	switch row.Type {
+	case "movie":
+		entry.MediaType = "movie"
+		entry.Year = meta.Year
+		applyPlexGuids(&entry, meta)
+	case "episode":
+		entry.MediaType = "episode"
+		entry.GrandparentTitle = row.GrandparentTitle
+		entry.SeasonNumber = row.ParentIndex
+		entry.EpisodeNumber = row.Index
+		applyPlexGuids(&entry, library[plexRatingKey(row.GrandparentRatingKey, row.GrandparentKey)])
+	default:
+		return historyEntry{}, false
+	}
+	return entry, true
+}
+
+func applyPlexGuids(entry *historyEntry, item clients.PlexMetadata) {
+	for _, guid := range item.Guids {
+		tmdbID, tvdbID, imdbID := extractIDsFromGuid(guid.ID)
+		if tmdbID > 0 {
+			entry.TMDBID = tmdbID
+		}
+		if tvdbID > 0 {
+			entry.TVDBID = tvdbID
+		}
+		if imdbID != "" {
+			entry.IMDBID = imdbID
+		}
+	}
+	if entry.Guid == "" {
+		entry.Guid = item.Guid
+	}
+}
+
+// plexRatingKey prefers an explicit ratingKey and falls back to the last
+// segment of a /library/metadata/<id> key.
+func plexRatingKey(ratingKey string, key string) string {
+	if ratingKey != "" {
+		return ratingKey
+	}
+	key = strings.TrimSuffix(key, "/children")
+	if idx := strings.LastIndex(key, "/"); idx >= 0 {
+		return key[idx+1:]
+	}
+	return key
+}
@@ -0,0 +1,79 @@
+package scan
+
+import (
+	"fmt"
+	"sort"
+
+	"go-unraid-clean/internal/diskspace"
+	"go-unraid-clean/internal/report"
+)
+
+func reclaimTarget(opts Options) (int64, error) {
+	if opts.UntilFreePercent <= 0 {
+		return opts.ReclaimBytes, nil
+	}
+	if opts.FreeSpacePath == "" {
+		return 0, fmt.Errorf("until-free requires a free space path")
+	}
+	stats, err := diskspace.Usage(opts.FreeSpacePath)
+	if err != nil {
+		return 0, err
+	}
+	target := stats.TargetForFreePercent(opts.UntilFreePercent)
+	if opts.ReclaimBytes > target {
+		target = opts.ReclaimBytes
+	}
+	return target, nil
+}
+
+// selectForReclaim keeps the highest-scored items until target is reached.
+// With disks set, only the bytes stored on those disks count. Kept and
+// protected items (in_progress mark mode) are dropped first since they would
+// never free anything. Copies of a title in other instances are grouped
+// before counting, so a selected title comes with all of its flagged copies.
+func selectForReclaim(rep *report.Report, target int64, disks []string) bool {
+	candidates := rep.Items[:0]
+	for _, item := range rep.Items {
+		if item.Action == report.ActionKeep || item.Protection != "" {
+			continue
+		}
+		candidates = append(candidates, item)
+	}
+	rep.Items = candidates
+	sort.SliceStable(rep.Items, func(i, j int) bool {
+		return rep.Items[i].Score > rep.Items[j].Score
+	})
+	rep.Items = groupCopies(rep.Items)
+	var cumulative int64
+	selected := 0
+	for idx := range rep.Items {
+		if cumulative >= target && (idx == 0 || !sameCopy(rep.Items[idx-1], rep.Items[idx])) {
+			break
+		}
+		cumulative += reclaimValue(rep.Items[idx], disks)
+		rep.Items[idx].CumulativeBytes = cumulative
+		selected++
+	}
+	rep.Items = rep.Items[:selected]
+	rep.ReclaimTargetBytes = target
+	return cumulative >= target
+}
+
+func reclaimValue(item report.Item, disks []string) int64 {
+	if len(disks) == 0 {
+		return item.Reclaimable()
+	}
+	return min(item.Reclaimable(), item.OnDisks(disks))
+}
+
+// filterByDisk keeps items with files on at least one of disks. Items whose
+// disks could not be resolved are dropped.
+func filterByDisk(items []report.Item, disks []string) []report.Item {
+	out := items[:0]
+	for _, item := range items {
+		if item.OnDisks(disks) > 0 {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/fsinspect"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/pathmap"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/unraid"
+)
+
+// inspectItems measures the files behind each item on the local filesystem
+// and records what a delete would free once hardlinks into download or seed
+// folders are excluded, plus which Unraid disks hold the files. Items whose
+// path is not visible from here keep reclaimable equal to size.
+func inspectItems(ctx context.Context, cfg config.Config, lib library, items []report.Item) {
+	log := logging.L()
+	paths := pathmap.FromConfig(cfg)
+	layout, err := unraid.Detect(cfg.Unraid.MountRoot)
+	if err != nil {
+		log.Debug().Err(err).Str("mount_root", cfg.Unraid.MountRoot).Msg("No Unraid disks found; skipping per-disk breakdown")
+		layout = nil
+	}
+	inspected, missing := 0, 0
+	for i := range items {
+		item := &items[i]
+		files, err := localPaths(ctx, lib, paths, *item)
+		if err != nil {
+			log.Warn().Err(err).Str("title", item.Title).Msg("Unable to list item files")
+			continue
+		}
+		usage, err := fsinspect.Inspect(files...)
+		if len(files) == 0 || errors.Is(err, fs.ErrNotExist) {
+			missing++
+			log.Debug().Str("title", item.Title).Str("path", item.LocalPath).Msg("Path not found locally; skipping hardlink check")
+			continue
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("title", item.Title).Msg("Unable to inspect files")
+			continue
+		}
+		inspected++
+		reclaimable := min(item.SizeBytes, usage.ReclaimableBytes)
+		item.ReclaimableBytes = &reclaimable
+		if usage.LinkedBytes > 0 {
+			log.Debug().Str("title", item.Title).Int64("linked_bytes", usage.LinkedBytes).Msg("Files are hardlinked elsewhere")
+		}
+		if layout != nil {
+			disks, err := layout.Locate(files)
+			if err != nil {
+				log.Warn().Err(err).Str("title", item.Title).Msg("Unable to locate files on Unraid disks")
+				continue
+			}
+			for _, d := range disks {
+				item.Disks = append(item.Disks, report.DiskBytes{Disk: d.Disk, Bytes: d.Bytes})
+			}
+		}
+	}
+	if missing > 0 {
+		log.Info().Int("missing", missing).Int("inspected", inspected).Msg("Some item paths are not visible locally; their reclaimable size equals size on disk")
+	}
+}
+
+// localPaths returns the host paths behind an item: its folder, or for a
+// season the episode files of that season.
+func localPaths(ctx context.Context, lib library, paths *pathmap.Set, item report.Item) ([]string, error) {
+	if item.Type != "season" || item.SonarrID == nil || item.SeasonNumber == nil {
+		if item.LocalPath == "" {
+			return nil, nil
+		}
+		return []string{item.LocalPath}, nil
+	}
+	sonarr, err := lib.arr.SonarrFor(item.Instance)
+	if err != nil {
+		return nil, err
+	}
+	files, err := sonarr.EpisodeFiles(ctx, *item.SonarrID)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, file := range files {
+		if file.SeasonNumber == *item.SeasonNumber && file.Path != "" {
+			out = append(out, paths.Sonarr(item.Instance).Map(file.Path))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,48 @@
+package scan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/report"
+)
+
+// Hardlink accounting itself is covered by the fsinspect tests; this checks
+// how inspectItems turns the result into an item's reclaimable size.
+func TestInspectItemsReclaimable(t *testing.T) {
+	root := t.TempDir()
+	movie := filepath.Join(root, "movies", "Movie (2020)")
+	if err := os.MkdirAll(movie, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(movie, "movie.mkv"), make([]byte, 100), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	items := []report.Item{
+		// Radarr's size matches the disk.
+		{Type: "movie", Title: "matching", LocalPath: movie, SizeBytes: 100},
+		// Radarr reports less than the folder holds: clamp to its size.
+		{Type: "movie", Title: "clamped", LocalPath: movie, SizeBytes: 80},
+		// Not visible locally: reclaimable stays unset and defaults to size.
+		{Type: "movie", Title: "missing", LocalPath: filepath.Join(root, "movies", "Missing"), SizeBytes: 70},
+	}
+	cfg := config.Config{Unraid: config.Unraid{MountRoot: filepath.Join(root, "no-unraid")}}
+	inspectItems(context.Background(), cfg, library{}, items)
+
+	want := map[string]int64{"matching": 100, "clamped": 80, "missing": 70}
+	for _, item := range items {
+		if got := item.Reclaimable(); got != want[item.Title] {
+			t.Errorf("%s: Reclaimable() = %d, want %d", item.Title, got, want[item.Title])
+		}
+	}
+	if items[0].ReclaimableBytes == nil || items[1].ReclaimableBytes == nil {
+		t.Error("inspected items have no reclaimable_bytes")
+	}
+	if items[2].ReclaimableBytes != nil {
+		t.Errorf("missing: ReclaimableBytes = %d, want unset", *items[2].ReclaimableBytes)
+	}
+}
@@ -0,0 +1,133 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+// requestIndex maps Overseerr requests to TMDB (movies) and TVDB (series)
+// IDs, keeping the most recent request per title.
+type requestIndex struct {
+	movies map[int]clients.OverseerrRequest
+	series map[int]clients.OverseerrRequest
+}
+
+func fetchRequests(ctx context.Context, cfg config.Config) ([]clients.OverseerrRequest, error) {
+	log := logging.L()
+	overseerr, err := clients.NewOverseerrClient(cfg.Overseerr.BaseURL, cfg.Overseerr.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Msg("Fetching Overseerr requests")
+	requests, err := overseerr.Requests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Int("count", len(requests)).Msg("Loaded Overseerr requests")
+	return requests, nil
+}
+
+func newRequestIndex(requests []clients.OverseerrRequest) *requestIndex {
+	idx := &requestIndex{
+		movies: map[int]clients.OverseerrRequest{},
+		series: map[int]clients.OverseerrRequest{},
+	}
+	keep := func(m map[int]clients.OverseerrRequest, id int, request clients.OverseerrRequest) {
+		if id <= 0 {
+			return
+		}
+		if prev, ok := m[id]; ok && !requestedAfter(request, prev) {
+			return
+		}
+		m[id] = request
+	}
+	for _, request := range requests {
+		switch request.Type {
+		case "movie":
+			keep(idx.movies, request.Media.TMDBID, request)
+		case "tv":
+			keep(idx.series, request.Media.TVDBID, request)
+		}
+	}
+	return idx
+}
+
+func requestedAfter(a, b clients.OverseerrRequest) bool {
+	at := parseTime(a.CreatedAt)
+	bt := parseTime(b.CreatedAt)
+	if at == nil {
+		return false
+	}
+	return bt == nil || at.After(*bt)
+}
+
+func (r *requestIndex) lookup(item report.Item) (clients.OverseerrRequest, bool) {
+	switch item.Type {
+	case "movie":
+		if item.TMDBID != nil {
+			request, ok := r.movies[*item.TMDBID]
+			return request, ok
+		}
+	case "series", "season":
+		if item.TVDBID != nil {
+			request, ok := r.series[*item.TVDBID]
+			return request, ok
+		}
+	}
+	return clients.OverseerrRequest{}, false
+}
+
+// applyRequest copies requester details onto an item.
+func (e *evaluator) applyRequest(item *report.Item) {
+	request, ok := e.requests.lookup(*item)
+	if !ok {
+		return
+	}
+	item.RequestedBy = requesterName(request.RequestedBy)
+	item.RequestedAt = parseTime(request.CreatedAt)
+}
+
+// requestHold returns a skip reason while a requested item is still within
+// protect_days, or within requester_grace_days and unwatched by the requester.
+func (e *evaluator) requestHold(item report.Item, watchers map[string]int64) string {
+	if item.RequestedAt == nil {
+		return ""
+	}
+	ageDays := e.now.Sub(*item.RequestedAt).Hours() / 24
+	if e.overseerr.ProtectDays > 0 && ageDays < float64(e.overseerr.ProtectDays) {
+		return fmt.Sprintf("requested by %s %.0f days ago", item.RequestedBy, ageDays)
+	}
+	if e.overseerr.RequesterGraceDays > 0 && ageDays < float64(e.overseerr.RequesterGraceDays) {
+		request, _ := e.requests.lookup(item)
+		if !requesterWatched(request.RequestedBy, watchers) {
+			return fmt.Sprintf("requester %s has not watched it yet", item.RequestedBy)
+		}
+	}
+	return ""
+}
+
+func requesterName(user clients.OverseerrUser) string {
+	for _, name := range []string{user.DisplayName, user.PlexUsername, user.JellyfinUsername, user.Username, user.Email} {
+		if name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("user-%d", user.ID)
+}
+
+func requesterWatched(user clients.OverseerrUser, watchers map[string]int64) bool {
+	for watcher := range watchers {
+		for _, name := range []string{user.DisplayName, user.PlexUsername, user.JellyfinUsername, user.Username, user.Email} {
+			if name != "" && strings.EqualFold(strings.TrimSpace(watcher), strings.TrimSpace(name)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-unraid-clean/internal/arr"
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/fsinspect"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/pathmap"
+	"go-unraid-clean/internal/report"
+)
+
+type Revalidator struct {
+	arr     *arr.Clients
+	eval    *evaluator
+	paths   *pathmap.Set
+	orphans *OrphanGuard
+}
+
+func NewRevalidator(ctx context.Context, cfg config.Config) (*Revalidator, error) {
+	arrs, err := arr.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.L().Info().Msg("Fetching watch history for revalidation")
+	now := time.Now().UTC()
+	data, err := fetchActivity(ctx, cfg, now)
+	if err != nil {
+		return nil, err
+	}
+	eval, err := newEvaluator(cfg, data, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Radarr) > 1 || len(cfg.Sonarr) > 1 {
+		// Rules may depend on copies in other instances.
+		movies, series, err := fetchInstances(ctx, arrs)
+		if err != nil {
+			return nil, err
+		}
+		eval.copies = newCopyIndex(movies, series)
+	}
+	return &Revalidator{
+		arr:   arrs,
+		eval:  eval,
+		paths: pathmap.FromConfig(cfg),
+	}, nil
+}
+
+func (r *Revalidator) Check(ctx context.Context, item report.Item) (string, error) {
+	var fresh report.Item
+	var skip string
+	switch item.Type {
+	case "movie":
+		if item.RadarrID == nil {
+			return "", fmt.Errorf("missing radarr_id")
+		}
+		radarr, err := r.arr.RadarrFor(item.Instance)
+		if err != nil {
+			return "", err
+		}
+		movie, err := radarr.Movie(ctx, *item.RadarrID)
+		if errors.Is(err, clients.ErrNotFound) {
+			return "no longer in " + radarr.Name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		fresh, skip = r.eval.movieItem(radarr.Name, *movie)
+	case "series":
+		if item.SonarrID == nil {
+			return "", fmt.Errorf("missing sonarr_id")
+		}
+		sonarr, err := r.arr.SonarrFor(item.Instance)
+		if err != nil {
+			return "", err
+		}
+		show, err := sonarr.SeriesByID(ctx, *item.SonarrID)
+		if errors.Is(err, clients.ErrNotFound) {
+			return "no longer in " + sonarr.Name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		fresh, skip = r.eval.seriesItem(sonarr.Name, show.SonarrSeries)
+	case "season":
+		if item.SonarrID == nil || item.SeasonNumber == nil {
+			return "", fmt.Errorf("missing sonarr_id or season_number")
+		}
+		sonarr, err := r.arr.SonarrFor(item.Instance)
+		if err != nil {
+			return "", err
+		}
+		show, err := sonarr.SeriesByID(ctx, *item.SonarrID)
+		if errors.Is(err, clients.ErrNotFound) {
+			return "no longer in " + sonarr.Name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		files, err := sonarr.EpisodeFiles(ctx, *item.SonarrID)
+		if err != nil {
+			return "", err
+		}
+		skip = fmt.Sprintf("season %d no longer in %s", *item.SeasonNumber, sonarr.Name)
+		for _, season := range show.Seasons {
+			if season.SeasonNumber == *item.SeasonNumber {
+				fresh, skip = r.eval.seasonItem(sonarr.Name, *show, season, seasonsAdded(files))
+				break
+			}
+		}
+	case "orphan":
+		return r.checkOrphan(ctx, item)
+	default:
+		return "", fmt.Errorf("unsupported item type: %s", item.Type)
+	}
+	if skip != "" {
+		return skip, nil
+	}
+	if fresh.Protection != "" {
+		return fresh.Protection, nil
+	}
+	if filepath.Clean(fresh.Path) != filepath.Clean(item.Path) {
+		return fmt.Sprintf("path changed from %s to %s", item.Path, fresh.Path), nil
+	}
+	if fresh.SizeBytes != item.SizeBytes {
+		return fmt.Sprintf("size changed from %d to %d bytes", item.SizeBytes, fresh.SizeBytes), nil
+	}
+	return "", nil
+}
+
+// checkOrphan confirms an orphan still exists, is still untracked inside a
+// root folder and has not changed size. The tracked library is fetched once
+// per revalidator.
+func (r *Revalidator) checkOrphan(ctx context.Context, item report.Item) (string, error) {
+	if item.LocalPath == "" {
+		return "", fmt.Errorf("missing local_path")
+	}
+	local := filepath.Clean(item.LocalPath)
+	if _, err := os.Lstat(local); errors.Is(err, fs.ErrNotExist) {
+		return "no longer on disk", nil
+	} else if err != nil {
+		return "", err
+	}
+	if r.orphans == nil {
+		guard, err := NewOrphanGuard(ctx, r.arr, r.paths)
+		if err != nil {
+			return "", err
+		}
+		r.orphans = guard
+	}
+	if err := r.orphans.Check(local); err != nil {
+		return err.Error(), nil
+	}
+	usage, err := fsinspect.Inspect(local)
+	if err != nil {
+		return "", err
+	}
+	if usage.TotalBytes != item.SizeBytes {
+		return fmt.Sprintf("size changed from %d to %d bytes", item.SizeBytes, usage.TotalBytes), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/expr"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+// TestRule evaluates an expression against every movie and series with files
+// on disk and returns the matches. Exceptions and other custom rules are not
+// applied; Reason shows what the built-in rules would decide on their own.
+func TestRule(ctx context.Context, cfg config.Config, when string) (*report.Report, error) {
+	prog, err := expr.Compile(when, RuleFields)
+	if err != nil {
+		return nil, err
+	}
+	lib, err := fetchLibrary(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Rules.Custom = nil
+	eval, err := newEvaluator(cfg, lib.activityData, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	eval.copies = newCopyIndex(lib.movies, lib.series)
+
+	rep := &report.Report{GeneratedAt: eval.now, Items: []report.Item{}}
+	check := func(item report.Item, facts itemFacts) error {
+		item.Score, item.ScoreBreakdown = scoreItem(item, eval.now, cfg.Rules.Scoring)
+		ok, err := prog.Match(ruleEnv(item, facts, eval.now))
+		if err != nil {
+			return fmt.Errorf("%s: %w", item.Title, err)
+		}
+		if ok {
+			rep.Items = append(rep.Items, item)
+		}
+		return nil
+	}
+
+	for _, movie := range lib.movies {
+		if !movie.HasFile || movie.SizeOnDisk == 0 {
+			continue
+		}
+		if err := check(eval.movieCandidate(movie.instance, movie.RadarrMovie), movieFacts(movie.RadarrMovie)); err != nil {
+			return nil, err
+		}
+	}
+	for _, show := range lib.series {
+		if show.Statistics.SizeOnDisk == 0 {
+			continue
+		}
+		if err := check(eval.seriesCandidate(show.instance, show.SonarrSeries), seriesFacts(show.SonarrSeries)); err != nil {
+			return nil, err
+		}
+	}
+	logging.L().Debug().Int("count", len(rep.Items)).Msg("Items matching rule")
+	return rep, nil
+}
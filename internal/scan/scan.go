@@ -7,7 +7,7 @@ import (
 	"strings"
 	"time"
 
-	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/arr"
 	"go-unraid-clean/internal/config"
 	"go-unraid-clean/internal/logging"
 	"go-unraid-clean/internal/report"
@@ -20,161 +20,126 @@ const (
 )
 
 type Options struct {
-	SortBy    string
-	SortOrder string
+	SortBy           string
+	SortOrder        string
+	ReclaimBytes     int64
+	UntilFreePercent float64
+	FreeSpacePath    string
+	Disks            []string
+}
+
+type library struct {
+	activityData
+	arr    *arr.Clients
+	movies []instanceMovie
+	series []instanceSeries
 }
 
 func Run(ctx context.Context, cfg config.Config, opts Options) (*report.Report, error) {
 	log := logging.L()
-	radarr, err := clients.NewRadarrClient(cfg.Radarr.BaseURL, cfg.Radarr.APIKey)
-	if err != nil {
+	if _, err := compileCustomRules(cfg.Rules.Custom); err != nil {
 		return nil, err
 	}
-	sonarr, err := clients.NewSonarrClient(cfg.Sonarr.BaseURL, cfg.Sonarr.APIKey)
+	lib, err := fetchLibrary(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
-	tautulli, err := clients.NewTautulliClient(cfg.Tautulli.BaseURL, cfg.Tautulli.APIKey)
+	eval, err := newEvaluator(cfg, lib.activityData, time.Now().UTC())
 	if err != nil {
 		return nil, err
 	}
-
-	log.Info().Msg("Fetching Radarr movies")
-	movies, err := radarr.Movies(ctx)
-	if err != nil {
-		return nil, err
-	}
-	log.Debug().Int("count", len(movies)).Msg("Loaded Radarr movies")
-	log.Info().Msg("Fetching Sonarr series")
-	series, err := sonarr.Series(ctx)
-	if err != nil {
-		return nil, err
-	}
-	log.Debug().Int("count", len(series)).Msg("Loaded Sonarr series")
-	log.Info().Msg("Fetching Tautulli history")
-	entries, err := tautulli.History(ctx)
-	if err != nil {
-		return nil, err
-	}
-	log.Debug().Int("count", len(entries)).Msg("Loaded Tautulli history entries")
-
-	activity, watch := buildIndexes(entries, cfg.Rules.ActivityMinPercent)
-	exceptions := newExceptionIndex(cfg)
+	eval.copies = newCopyIndex(lib.movies, lib.series)
 
 	rep := &report.Report{
-		GeneratedAt: time.Now().UTC(),
+		GeneratedAt: eval.now,
 		Items:       []report.Item{},
 	}
-	cutoffWatch := time.Duration(cfg.Rules.InactivityDaysAfterWatch) * 24 * time.Hour
-	cutoffNever := time.Duration(cfg.Rules.NeverWatchedDaysSinceAdded) * 24 * time.Hour
-
-	now := time.Now().UTC()
-
-	for _, movie := range movies {
-		if !movie.HasFile || movie.SizeOnDisk == 0 {
-			continue
-		}
-		if exceptions.isMovieException(movie.ID, movie.TMDBID, movie.IMDBID, movie.Title, movie.Path) {
-			log.Debug().Str("title", movie.Title).Msg("Skipping movie due to exception")
-			continue
-		}
-
-		titleKey := normalizeTitleYear(movie.Title, movie.Year)
-		firstActivity := activity.movieFirstActivity(movie.TMDBID, movie.IMDBID, titleKey)
-		lastActivity := activity.movieLastActivity(movie.TMDBID, movie.IMDBID, titleKey)
-		topUsers := watch.movieTopUsers(movie.TMDBID, movie.IMDBID, titleKey, 2)
-		topUsersOut, topTotal := toReportUsers(topUsers)
-		totalWatchHours := float64(watch.movieTotalSeconds(movie.TMDBID, movie.IMDBID, titleKey)) / 3600
-		addedAt := parseTime(movie.Added)
 
-		reason := evaluate(now, lastActivity, addedAt, cutoffWatch, cutoffNever, totalWatchHours, cfg.Rules)
-		if reason == "" {
+	for _, movie := range lib.movies {
+		item, skip := eval.movieItem(movie.instance, movie.RadarrMovie)
+		if skip != "" {
+			log.Debug().Str("title", movie.Title).Str("instance", movie.instance).Str("reason", skip).Msg("Skipping movie")
 			continue
 		}
-
-		id := movie.ID
-		var tmdbPtr *int
-		if movie.TMDBID > 0 {
-			tmdb := movie.TMDBID
-			tmdbPtr = &tmdb
-		}
-		rep.Items = append(rep.Items, report.Item{
-			Type:               "movie",
-			Title:              fmt.Sprintf("%s (%d)", movie.Title, movie.Year),
-			RadarrID:           &id,
-			TMDBID:             tmdbPtr,
-			IMDBID:             movie.IMDBID,
-			Path:               movie.Path,
-			SizeBytes:          movie.SizeOnDisk,
-			AddedAt:            addedAt,
-			FirstActivityAt:    firstActivity,
-			LastActivityAt:     lastActivity,
-			TopUsers:           topUsersOut,
-			TopUsersTotalHours: topTotal,
-			TotalWatchHours:    totalWatchHours,
-			Reason:             reason,
-		})
+		rep.Items = append(rep.Items, item)
 	}
 	log.Info().Int("count", len(rep.Items)).Msg("Movies flagged for review")
 
-	for _, show := range series {
-		if show.Statistics.SizeOnDisk == 0 {
+	for _, show := range lib.series {
+		item, skip := eval.seriesItem(show.instance, show.SonarrSeries)
+		if skip == "" {
+			rep.Items = append(rep.Items, item)
 			continue
 		}
-		if exceptions.isSeriesException(show.ID, show.TVDBID, show.IMDBID, show.Title, show.Path) {
-			log.Debug().Str("title", show.Title).Msg("Skipping series due to exception")
+		log.Debug().Str("title", show.Title).Str("instance", show.instance).Str("reason", skip).Msg("Skipping series")
+		if !cfg.Rules.Seasons.Enabled || (skip != skipRulesNotMet && skip != skipNotEnded) {
 			continue
 		}
-		if cfg.Rules.SeriesEndedOnly && !isEndedStatus(show.Status) {
-			log.Debug().Str("title", show.Title).Str("status", show.Status).Msg("Skipping series because status is not ended")
-			continue
+		sonarr, err := lib.arr.SonarrFor(show.instance)
+		if err != nil {
+			return nil, err
 		}
-
-		titleKey := normalizeTitle(show.Title)
-		firstActivity := activity.seriesFirstActivity(show.TVDBID, show.IMDBID, titleKey)
-		lastActivity := activity.seriesLastActivity(show.TVDBID, show.IMDBID, titleKey)
-		topUsers := watch.seriesTopUsers(show.TVDBID, show.IMDBID, titleKey, 2)
-		topUsersOut, topTotal := toReportUsers(topUsers)
-		totalWatchHours := float64(watch.seriesTotalSeconds(show.TVDBID, show.IMDBID, titleKey)) / 3600
-		addedAt := parseTime(show.Added)
-		reason := evaluate(now, lastActivity, addedAt, cutoffWatch, cutoffNever, totalWatchHours, cfg.Rules)
-		if reason == "" {
-			continue
+		detail, err := sonarr.SeriesByID(ctx, show.ID)
+		if err != nil {
+			return nil, err
 		}
-
-		id := show.ID
-		var tvdbPtr *int
-		if show.TVDBID > 0 {
-			tvdb := show.TVDBID
-			tvdbPtr = &tvdb
+		files, err := sonarr.EpisodeFiles(ctx, show.ID)
+		if err != nil {
+			return nil, err
 		}
-		rep.Items = append(rep.Items, report.Item{
-			Type:               "series",
-			Title:              show.Title,
-			SonarrID:           &id,
-			TVDBID:             tvdbPtr,
-			IMDBID:             show.IMDBID,
-			Path:               show.Path,
-			SizeBytes:          show.Statistics.SizeOnDisk,
-			AddedAt:            addedAt,
-			FirstActivityAt:    firstActivity,
-			LastActivityAt:     lastActivity,
-			TopUsers:           topUsersOut,
-			TopUsersTotalHours: topTotal,
-			TotalWatchHours:    totalWatchHours,
-			SeriesStatus:       show.Status,
-			Reason:             reason,
-		})
+		rep.Items = append(rep.Items, eval.seasonItems(show.instance, *detail, seasonsAdded(files))...)
 	}
 	log.Info().Int("count", len(rep.Items)).Msg("Total items flagged for review")
+	inspectItems(ctx, cfg, lib, rep.Items)
+	annotateTorrents(ctx, cfg, lib, rep.Items)
+	if len(opts.Disks) > 0 {
+		rep.Items = filterByDisk(rep.Items, opts.Disks)
+		log.Info().Int("count", len(rep.Items)).Strs("disks", opts.Disks).Msg("Items on selected disks")
+	}
+
+	target, err := reclaimTarget(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ReclaimBytes > 0 || opts.UntilFreePercent > 0 {
+		if target == 0 {
+			log.Info().Msg("Free space target already met; nothing to reclaim")
+			rep.Items = []report.Item{}
+			return rep, nil
+		}
+		if !selectForReclaim(rep, target, opts.Disks) {
+			log.Warn().Int64("target_bytes", target).Msg("All flagged items together do not reach the reclaim target")
+		}
+		log.Info().Int("count", len(rep.Items)).Int64("target_bytes", target).Msg("Selected items for reclaim target")
+		return rep, nil
+	}
 
 	if err := sortReport(rep, opts); err != nil {
 		return nil, err
 	}
+	rep.Items = groupCopies(rep.Items)
 
 	return rep, nil
 }
 
+func fetchLibrary(ctx context.Context, cfg config.Config) (library, error) {
+	arrs, err := arr.New(cfg)
+	if err != nil {
+		return library{}, err
+	}
+
+	lib := library{arr: arrs}
+	lib.movies, lib.series, err = fetchInstances(ctx, arrs)
+	if err != nil {
+		return library{}, err
+	}
+	lib.activityData, err = fetchActivity(ctx, cfg, time.Now().UTC())
+	if err != nil {
+		return library{}, err
+	}
+	return lib, nil
+}
+
 func isEndedStatus(status string) bool {
 	switch strings.ToLower(strings.TrimSpace(status)) {
 	case "ended":
@@ -184,46 +149,109 @@ func isEndedStatus(status string) bool {
 	}
 }
 
-func buildIndexes(entries []map[string]any, minPercent int) (*activityIndex, *watchIndex) {
+// historyKey identifies the movie or series a history entry belongs to.
+type historyKey struct {
+	kind     string
+	tmdbID   int
+	tvdbID   int
+	imdbID   string
+	titleKey string
+}
+
+func historyKeys(entry historyEntry) (historyKey, bool) {
+	switch entry.MediaType {
+	case "movie":
+		tmdbID, _, imdbID := extractIDsFromGuid(entry.Guid)
+		if tmdbID == 0 {
+			tmdbID = entry.TMDBID
+		}
+		if imdbID == "" {
+			imdbID = entry.IMDBID
+		}
+		return historyKey{kind: "movie", tmdbID: tmdbID, imdbID: imdbID, titleKey: normalizeTitleYear(entry.Title, entry.Year)}, true
+	case "episode":
+		_, tvdbID, imdbID := extractIDsFromGuid(entry.GrandparentGuid)
+		if tvdbID == 0 {
+			_, tvdbID, _ = extractIDsFromGuid(entry.ParentGuid)
+		}
+		if tvdbID == 0 {
+			_, tvdbID, _ = extractIDsFromGuid(entry.Guid)
+		}
+		if tvdbID == 0 {
+			tvdbID = entry.TVDBID
+		}
+		if imdbID == "" {
+			_, _, imdbID = extractIDsFromGuid(entry.Guid)
+		}
+		if imdbID == "" {
+			imdbID = entry.IMDBID
+		}
+		return historyKey{kind: "series", tvdbID: tvdbID, imdbID: imdbID, titleKey: normalizeTitle(entry.GrandparentTitle)}, true
+	case "show", "series":
+		_, tvdbID, imdbID := extractIDsFromGuid(entry.Guid)
+		if tvdbID == 0 {
+			tvdbID = entry.TVDBID
+		}
+		if imdbID == "" {
+			imdbID = entry.IMDBID
+		}
+		return historyKey{kind: "series", tvdbID: tvdbID, imdbID: imdbID, titleKey: normalizeTitle(entry.Title)}, true
+	}
+	return historyKey{}, false
+}
+
+func buildIndexes(entries []historyEntry, minPercent int) (*activityIndex, *watchIndex, *seasonIndex) {
 	activity := newActivityIndex()
 	watch := newWatchIndex()
-	for _, raw := range entries {
-		entry, ok := parseHistoryEntry(raw)
-		if !ok {
+	seasons := newSeasonIndex()
+	for _, entry := range entries {
+		if entry.PercentComplete > 0 && entry.PercentComplete < minPercent {
 			continue
 		}
-		if entry.PercentComplete > 0 && entry.PercentComplete < minPercent {
+		key, ok := historyKeys(entry)
+		if !ok {
 			continue
 		}
-
-		switch entry.MediaType {
+		switch key.kind {
 		case "movie":
-			tmdbID, _, imdbID := extractIDsFromGuid(entry.Guid)
-			titleKey := normalizeTitleYear(entry.Title, entry.Year)
-			activity.recordMovie(tmdbID, imdbID, titleKey, entry.When)
-			watch.recordMovie(tmdbID, imdbID, titleKey, entry.User, entry.WatchSeconds)
-		case "episode":
-			_, tvdbID, imdbID := extractIDsFromGuid(entry.GrandparentGuid)
-			if tvdbID == 0 {
-				_, tvdbID, _ = extractIDsFromGuid(entry.ParentGuid)
-			}
-			if tvdbID == 0 {
-				_, tvdbID, _ = extractIDsFromGuid(entry.Guid)
+			activity.recordMovie(key.tmdbID, key.imdbID, key.titleKey, entry.When)
+			watch.recordMovie(key.tmdbID, key.imdbID, key.titleKey, entry.User, entry.WatchSeconds)
+		case "series":
+			activity.recordSeries(key.tvdbID, key.imdbID, key.titleKey, entry.When)
+			watch.recordSeries(key.tvdbID, key.imdbID, key.titleKey, entry.User, entry.WatchSeconds)
+			if entry.MediaType == "episode" && entry.SeasonNumber > 0 {
+				seasons.record(key.tvdbID, key.imdbID, key.titleKey, entry.SeasonNumber, entry.EpisodeNumber, entry.User, entry.WatchSeconds, entry.When)
 			}
-			if imdbID == "" {
-				_, _, imdbID = extractIDsFromGuid(entry.Guid)
-			}
-			titleKey := normalizeTitle(entry.GrandparentTitle)
-			activity.recordSeries(tvdbID, imdbID, titleKey, entry.When)
-			watch.recordSeries(tvdbID, imdbID, titleKey, entry.User, entry.WatchSeconds)
-		case "show", "series":
-			_, tvdbID, imdbID := extractIDsFromGuid(entry.Guid)
-			titleKey := normalizeTitle(entry.Title)
-			activity.recordSeries(tvdbID, imdbID, titleKey, entry.When)
-			watch.recordSeries(tvdbID, imdbID, titleKey, entry.User, entry.WatchSeconds)
 		}
 	}
-	return activity, watch
+	return activity, watch, seasons
+}
+
+// buildProtectedIndex records items with a live session or an in-progress
+// history entry newer than window.
+func buildProtectedIndex(entries []historyEntry, live []historyEntry, now time.Time, window time.Duration) *activityIndex {
+	protected := newActivityIndex()
+	record := func(entry historyEntry) {
+		key, ok := historyKeys(entry)
+		if !ok {
+			return
+		}
+		switch key.kind {
+		case "movie":
+			protected.recordMovie(key.tmdbID, key.imdbID, key.titleKey, entry.When)
+		case "series":
+			protected.recordSeries(key.tvdbID, key.imdbID, key.titleKey, entry.When)
+		}
+	}
+	for _, entry := range live {
+		record(entry)
+	}
+	for _, entry := range entries {
+		if entry.InProgress && now.Sub(entry.When) <= window {
+			record(entry)
+		}
+	}
+	return protected
 }
 
 func evaluate(now time.Time, lastActivity *time.Time, addedAt *time.Time, cutoffWatch time.Duration, cutoffNever time.Duration, totalWatchHours float64, rules config.Rules) string {
@@ -275,6 +303,10 @@ func parseTime(value string) *time.Time {
 		utc := t.UTC()
 		return &utc
 	}
+	if t, err := time.Parse("2006-01-02 15:04:05", value); err == nil {
+		utc := t.UTC()
+		return &utc
+	}
 	return nil
 }
 
@@ -293,9 +325,9 @@ func sortReport(rep *report.Report, opts Options) error {
 	case "size":
 		sort.SliceStable(rep.Items, func(i, j int) bool {
 			if desc {
-				return rep.Items[i].SizeBytes > rep.Items[j].SizeBytes
+				return rep.Items[i].Reclaimable() > rep.Items[j].Reclaimable()
 			}
-			return rep.Items[i].SizeBytes < rep.Items[j].SizeBytes
+			return rep.Items[i].Reclaimable() < rep.Items[j].Reclaimable()
 		})
 	case "added":
 		sort.SliceStable(rep.Items, func(i, j int) bool {
@@ -324,6 +356,13 @@ func sortReport(rep *report.Report, opts Options) error {
 			}
 			return left.Before(right)
 		})
+	case "score":
+		sort.SliceStable(rep.Items, func(i, j int) bool {
+			if desc {
+				return rep.Items[i].Score > rep.Items[j].Score
+			}
+			return rep.Items[i].Score < rep.Items[j].Score
+		})
 	case "inactivity":
 		sort.SliceStable(rep.Items, func(i, j int) bool {
 			left := inactivityDays(rep.Items[i], rep.GeneratedAt)
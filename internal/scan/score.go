@@ -0,0 +1,53 @@
+package scan
+
+import (
+	"time"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/report"
+)
+
+const (
+	factorInactivityDays   = "inactivity_days"
+	factorNeverWatchedAge  = "never_watched_age"
+	factorWatchHoursPerGiB = "watch_hours_per_gib"
+	factorDistinctViewers  = "distinct_viewers"
+	factorSeriesEnded      = "series_ended"
+	factorSizeGiB          = "size_gib"
+)
+
+func scoreItem(item report.Item, generatedAt time.Time, weights config.Scoring) (float64, []report.ScoreFactor) {
+	sizeGiB := float64(item.SizeBytes) / (1024 * 1024 * 1024)
+	factors := []report.ScoreFactor{}
+	add := func(name string, value float64, weight float64) {
+		if value == 0 || weight == 0 {
+			return
+		}
+		factors = append(factors, report.ScoreFactor{
+			Factor:       name,
+			Value:        value,
+			Weight:       weight,
+			Contribution: value * weight,
+		})
+	}
+
+	if item.LastActivityAt != nil {
+		add(factorInactivityDays, inactivityDays(item, generatedAt), weights.InactivityDays)
+	} else {
+		add(factorNeverWatchedAge, inactivityDays(item, generatedAt), weights.NeverWatchedAge)
+	}
+	if sizeGiB > 0 {
+		add(factorWatchHoursPerGiB, item.TotalWatchHours/sizeGiB, weights.WatchHoursPerGiB)
+	}
+	add(factorDistinctViewers, float64(item.Viewers), weights.DistinctViewers)
+	if (item.Type == "series" || item.Type == "season") && isEndedStatus(item.SeriesStatus) {
+		add(factorSeriesEnded, 1, weights.SeriesEnded)
+	}
+	add(factorSizeGiB, sizeGiB, weights.SizeGiB)
+
+	var score float64
+	for _, factor := range factors {
+		score += factor.Contribution
+	}
+	return score, factors
+}
@@ -0,0 +1,185 @@
+package scan
+
+import (
+	"fmt"
+	"time"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+const (
+	reasonSeasonWatched = "season_watched"
+
+	skipSeasonRecent = "season files added recently"
+)
+
+type seasonKey struct {
+	series string
+	season int
+}
+
+type seasonActivity struct {
+	window   activityWindow
+	episodes map[int]bool
+	users    map[string]int64
+}
+
+// seasonIndex tracks activity per (series, season) from the history season
+// number (Tautulli's parent_media_index), keyed the same three ways as the series indexes.
+type seasonIndex struct {
+	seasons map[seasonKey]*seasonActivity
+}
+
+func newSeasonIndex() *seasonIndex {
+	return &seasonIndex{seasons: map[seasonKey]*seasonActivity{}}
+}
+
+func seriesKeys(tvdbID int, imdbID string, titleKey string) []string {
+	var keys []string
+	if tvdbID > 0 {
+		keys = append(keys, fmt.Sprintf("tvdb:%d", tvdbID))
+	}
+	if imdbID != "" {
+		keys = append(keys, "imdb:"+imdbID)
+	}
+	if titleKey != "" {
+		keys = append(keys, "title:"+titleKey)
+	}
+	return keys
+}
+
+func (s *seasonIndex) record(tvdbID int, imdbID string, titleKey string, season int, episode int, user string, seconds int64, when time.Time) {
+	for _, series := range seriesKeys(tvdbID, imdbID, titleKey) {
+		key := seasonKey{series: series, season: season}
+		entry, ok := s.seasons[key]
+		if !ok {
+			entry = &seasonActivity{
+				window:   activityWindow{First: when, Last: when},
+				episodes: map[int]bool{},
+				users:    map[string]int64{},
+			}
+			s.seasons[key] = entry
+		}
+		if when.Before(entry.window.First) {
+			entry.window.First = when
+		}
+		if when.After(entry.window.Last) {
+			entry.window.Last = when
+		}
+		if episode > 0 {
+			entry.episodes[episode] = true
+		}
+		if user != "" {
+			entry.users[user] += seconds
+		}
+	}
+}
+
+func (s *seasonIndex) lookup(tvdbID int, imdbID string, titleKey string, season int) *seasonActivity {
+	for _, series := range seriesKeys(tvdbID, imdbID, titleKey) {
+		if entry, ok := s.seasons[seasonKey{series: series, season: season}]; ok {
+			return entry
+		}
+	}
+	return nil
+}
+
+// seasonsAdded returns when each season's newest episode file was imported,
+// which is when the season arrived as far as the never-watched rule is
+// concerned.
+func seasonsAdded(files []clients.SonarrEpisodeFile) map[int]time.Time {
+	out := map[int]time.Time{}
+	for _, file := range files {
+		added := parseTime(file.DateAdded)
+		if added == nil {
+			continue
+		}
+		if added.After(out[file.SeasonNumber]) {
+			out[file.SeasonNumber] = *added
+		}
+	}
+	return out
+}
+
+// seasonItem evaluates one season of a series that was not flagged as a whole.
+// A season qualifies when every episode on disk was watched and activity is
+// older than the watch cutoff, or when nothing in it was ever watched and its
+// newest episode file is older than the never-watched cutoff.
+func (e *evaluator) seasonItem(instance string, show clients.SonarrSeriesDetail, season clients.SonarrSeason, added map[int]time.Time) (report.Item, string) {
+	if season.SeasonNumber == 0 || season.Statistics.EpisodeFileCount == 0 || season.Statistics.SizeOnDisk == 0 {
+		return report.Item{}, skipNoFiles
+	}
+	if e.exceptions.isSeriesException(instance, show.ID, show.TVDBID, show.IMDBID, show.Title, show.Path, e.paths.Sonarr(instance).Map(show.Path)) {
+		return report.Item{}, skipException
+	}
+
+	titleKey := normalizeTitle(show.Title)
+	activity := e.seasons.lookup(show.TVDBID, show.IMDBID, titleKey, season.SeasonNumber)
+	var addedAt *time.Time
+	if when, ok := added[season.SeasonNumber]; ok {
+		addedAt = &when
+	}
+	if activity == nil && (addedAt == nil || e.now.Sub(*addedAt) < e.cutoffNever) {
+		return report.Item{}, skipSeasonRecent
+	}
+
+	id := show.ID
+	number := season.SeasonNumber
+	item := report.Item{
+		Type:         "season",
+		Title:        fmt.Sprintf("%s - Season %d", show.Title, season.SeasonNumber),
+		Instance:     instance,
+		AlsoIn:       e.copies.seriesAlsoIn(show.TVDBID, instance),
+		SonarrID:     &id,
+		IMDBID:       show.IMDBID,
+		Path:         show.Path,
+		LocalPath:    e.paths.Sonarr(instance).Map(show.Path),
+		SizeBytes:    season.Statistics.SizeOnDisk,
+		AddedAt:      addedAt,
+		SeriesStatus: show.Status,
+		SeasonNumber: &number,
+		Action:       report.ActionDelete,
+	}
+	if show.TVDBID > 0 {
+		tvdb := show.TVDBID
+		item.TVDBID = &tvdb
+	}
+
+	if activity == nil {
+		item.Reason = reasonNeverWatched
+	} else {
+		first := activity.window.First
+		last := activity.window.Last
+		item.FirstActivityAt = &first
+		item.LastActivityAt = &last
+		item.TopUsers, item.TopUsersTotalHours = toReportUsers(topUsers(activity.users, 2))
+		item.TotalWatchHours = float64(sumUserTotals(activity.users)) / 3600
+		item.Viewers = len(activity.users)
+		if len(activity.episodes) >= season.Statistics.EpisodeFileCount && e.now.Sub(last) >= e.cutoffWatch {
+			item.Reason = reasonSeasonWatched
+		}
+	}
+	e.applyRequest(&item)
+	item, skip := e.decide(item, seriesFacts(show.SonarrSeries))
+	if skip == "" {
+		if hold := e.requestHold(item, e.seriesWatchers(show.SonarrSeries)); hold != "" {
+			return report.Item{}, hold
+		}
+	}
+	return e.protect(item, skip, e.seriesProtected(show.SonarrSeries))
+}
+
+func (e *evaluator) seasonItems(instance string, show clients.SonarrSeriesDetail, added map[int]time.Time) []report.Item {
+	var items []report.Item
+	for _, season := range show.Seasons {
+		item, skip := e.seasonItem(instance, show, season, added)
+		if skip != "" {
+			logging.L().Debug().Str("title", show.Title).Int("season", season.SeasonNumber).Str("reason", skip).Msg("Skipping season")
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -16,13 +16,45 @@ type historyEntry struct {
 	ParentGuid       string
 	GrandparentGuid  string
 	GrandparentTitle string
+	TMDBID           int
+	TVDBID           int
+	IMDBID           string
+	SeasonNumber     int
+	EpisodeNumber    int
 	PercentComplete  int
 	When             time.Time
 	User             string
 	WatchSeconds     int64
+	InProgress       bool
 }
 
+// inProgressMaxPercent is the completion below which a Tautulli history row
+// counts as started but not finished.
+const inProgressMaxPercent = 85
+
 func parseHistoryEntry(raw map[string]any) (historyEntry, bool) {
+	entry := parseHistoryFields(raw)
+	when, ok := getUnixTime(raw, "date", "stopped", "started", "last_viewed_at")
+	if !ok {
+		return entry, false
+	}
+	entry.When = when
+	entry.WatchSeconds = getWatchSeconds(raw, entry.PercentComplete)
+	entry.InProgress = entry.PercentComplete > 0 && entry.PercentComplete < inProgressMaxPercent
+	return entry, true
+}
+
+// parseSessionEntry converts a Tautulli get_activity session into an
+// in-progress entry observed at now.
+func parseSessionEntry(raw map[string]any, now time.Time) historyEntry {
+	entry := parseHistoryFields(raw)
+	entry.PercentComplete = getInt(raw, "progress_percent")
+	entry.When = now
+	entry.InProgress = true
+	return entry
+}
+
+func parseHistoryFields(raw map[string]any) historyEntry {
 	entry := historyEntry{}
 	entry.MediaType = getString(raw, "media_type")
 	entry.Title = getString(raw, "title", "full_title")
@@ -31,16 +63,11 @@ func parseHistoryEntry(raw map[string]any) (historyEntry, bool) {
 	entry.ParentGuid = getString(raw, "parent_guid")
 	entry.GrandparentGuid = getString(raw, "grandparent_guid")
 	entry.Year = getInt(raw, "year")
+	entry.SeasonNumber = getInt(raw, "parent_media_index")
+	entry.EpisodeNumber = getInt(raw, "media_index")
 	entry.PercentComplete = getInt(raw, "percent_complete", "percent")
 	entry.User = getUserString(raw)
-
-	when, ok := getUnixTime(raw, "date", "stopped", "started", "last_viewed_at")
-	if !ok {
-		return entry, false
-	}
-	entry.When = when
-	entry.WatchSeconds = getWatchSeconds(raw, entry.PercentComplete)
-	return entry, true
+	return entry
 }
 
 func getString(m map[string]any, keys ...string) string {
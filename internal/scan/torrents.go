@@ -0,0 +1,86 @@
+package scan
+
+import (
+	"context"
+
+	"go-unraid-clean/internal/clients"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+)
+
+// annotateTorrents matches flagged items to torrents through the downloadId
+// in Radarr/Sonarr history and estimates the bytes a delete would free.
+// Without a filesystem measurement, imports are assumed to be hardlinks, so
+// data shared with a torrent that stays in the client is not reclaimable;
+// with one, torrents that will be removed add their data back. Torrent
+// client errors only warn.
+func annotateTorrents(ctx context.Context, cfg config.Config, lib library, items []report.Item) {
+	if cfg.Torrent.Client == "" || len(items) == 0 {
+		return
+	}
+	log := logging.L()
+	client, err := clients.NewTorrentClient(cfg.Torrent.Client, cfg.Torrent.BaseURL, cfg.Torrent.Username, cfg.Torrent.Password)
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to create torrent client")
+		return
+	}
+	log.Info().Str("client", client.Name()).Msg("Fetching torrents")
+	list, err := client.Torrents(ctx)
+	if err != nil {
+		log.Warn().Err(err).Str("client", client.Name()).Msg("Unable to load torrents; reclaimable sizes not adjusted")
+		return
+	}
+	torrents := make(map[string]clients.Torrent, len(list))
+	for _, t := range list {
+		torrents[t.Hash] = t
+	}
+	log.Debug().Int("count", len(torrents)).Msg("Loaded torrents")
+
+	for i := range items {
+		item := &items[i]
+		hashes, err := itemTorrentHashes(ctx, lib, *item)
+		if err != nil {
+			log.Warn().Err(err).Str("title", item.Title).Msg("Unable to load download history")
+			continue
+		}
+		var kept, removed int64
+		for _, hash := range hashes {
+			t, ok := torrents[hash]
+			if !ok {
+				continue
+			}
+			info := report.TorrentInfo{
+				Hash:           t.Hash,
+				Name:           t.Name,
+				State:          t.State,
+				Seeding:        t.Seeding,
+				Ratio:          t.Ratio,
+				SeedingSeconds: t.SeedingSeconds,
+				SizeBytes:      t.SizeBytes,
+				GoalMet:        cfg.Torrent.GoalMet(t.Ratio, t.SeedingSeconds),
+			}
+			item.Torrents = append(item.Torrents, info)
+			if cfg.Torrent.RemoveOnDelete && info.GoalMet {
+				removed += t.SizeBytes
+			} else {
+				kept += t.SizeBytes
+			}
+		}
+		var reclaimable int64
+		if item.ReclaimableBytes != nil {
+			reclaimable = min(item.SizeBytes, *item.ReclaimableBytes+removed)
+		} else {
+			reclaimable = max(0, item.SizeBytes-kept)
+		}
+		item.ReclaimableBytes = &reclaimable
+	}
+}
+
+func itemTorrentHashes(ctx context.Context, lib library, item report.Item) ([]string, error) {
+	records, err := lib.arr.History(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	return clients.TorrentHashes(records), nil
+}
@@ -127,6 +127,44 @@ func (w *watchIndex) seriesTotalSeconds(tvdbID int, imdbID string, titleKey stri
 	return 0
 }
 
+func (w *watchIndex) movieViewerCount(tmdbID int, imdbID string, titleKey string) int {
+	if tmdbID > 0 {
+		if totals, ok := w.moviesByTMDB[tmdbID]; ok {
+			return len(totals)
+		}
+	}
+	if imdbID != "" {
+		if totals, ok := w.moviesByIMDB[imdbID]; ok {
+			return len(totals)
+		}
+	}
+	if titleKey != "" {
+		if totals, ok := w.moviesByTitleKey[titleKey]; ok {
+			return len(totals)
+		}
+	}
+	return 0
+}
+
+func (w *watchIndex) seriesViewerCount(tvdbID int, imdbID string, titleKey string) int {
+	if tvdbID > 0 {
+		if totals, ok := w.seriesByTVDB[tvdbID]; ok {
+			return len(totals)
+		}
+	}
+	if imdbID != "" {
+		if totals, ok := w.seriesByIMDB[imdbID]; ok {
+			return len(totals)
+		}
+	}
+	if titleKey != "" {
+		if totals, ok := w.seriesByTitleKey[titleKey]; ok {
+			return len(totals)
+		}
+	}
+	return 0
+}
+
 func recordUserTotals[K comparable](m map[K]map[string]int64, key K, user string, seconds int64) {
 	if user == "" || seconds <= 0 {
 		return
@@ -166,3 +204,41 @@ func sumUserTotals(totals map[string]int64) int64 {
 	}
 	return sum
 }
+
+func (w *watchIndex) movieUsers(tmdbID int, imdbID string, titleKey string) map[string]int64 {
+	if tmdbID > 0 {
+		if totals, ok := w.moviesByTMDB[tmdbID]; ok {
+			return totals
+		}
+	}
+	if imdbID != "" {
+		if totals, ok := w.moviesByIMDB[imdbID]; ok {
+			return totals
+		}
+	}
+	if titleKey != "" {
+		if totals, ok := w.moviesByTitleKey[titleKey]; ok {
+			return totals
+		}
+	}
+	return nil
+}
+
+func (w *watchIndex) seriesUsers(tvdbID int, imdbID string, titleKey string) map[string]int64 {
+	if tvdbID > 0 {
+		if totals, ok := w.seriesByTVDB[tvdbID]; ok {
+			return totals
+		}
+	}
+	if imdbID != "" {
+		if totals, ok := w.seriesByIMDB[imdbID]; ok {
+			return totals
+		}
+	}
+	if titleKey != "" {
+		if totals, ok := w.seriesByTitleKey[titleKey]; ok {
+			return totals
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// start is a Friday.
+var start = time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		spec string
+		from time.Time
+		want []string
+	}{
+		{"0 3 * * *", start, []string{"2026-10-17 03:00", "2026-10-18 03:00"}},
+		{"*/15 * * * *", start, []string{"2026-10-16 10:45", "2026-10-16 11:00"}},
+		{"5,35 9-11 * * *", start, []string{"2026-10-16 10:35", "2026-10-16 11:05", "2026-10-16 11:35", "2026-10-17 09:05"}},
+		{"0 0 10/10 * *", start, []string{"2026-10-20 00:00", "2026-10-30 00:00", "2026-11-10 00:00"}},
+		{"@hourly", start, []string{"2026-10-16 11:00"}},
+		{"@weekly", start, []string{"2026-10-18 00:00", "2026-10-25 00:00"}},
+		{"@monthly", start, []string{"2026-11-01 00:00", "2026-12-01 00:00"}},
+		{"@yearly", start, []string{"2027-01-01 00:00"}},
+		// Sunday may be written as 7.
+		{"0 0 * * 7", start, []string{"2026-10-18 00:00"}},
+		{"0 0 * * 5-7", start, []string{"2026-10-17 00:00", "2026-10-18 00:00", "2026-10-23 00:00"}},
+		// Both day fields restricted: either may match (the 1st, or a Monday).
+		{"0 0 1 * 1", start, []string{"2026-10-19 00:00", "2026-10-26 00:00", "2026-11-01 00:00", "2026-11-02 00:00"}},
+		// A day field starting with "*" is unrestricted for that rule, so
+		// "*/2" and a weekday must both match: odd days that are Mondays.
+		{"0 0 */2 * 1", start, []string{"2026-10-19 00:00", "2026-11-09 00:00", "2026-11-23 00:00"}},
+		// The 1st of the month on a Sunday, Wednesday or Saturday.
+		{"0 0 1 * */3", start, []string{"2026-11-01 00:00", "2027-05-01 00:00", "2027-08-01 00:00"}},
+		{"0 0 29 2 *", start, []string{"2028-02-29 00:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := tt.from
+			for _, want := range tt.want {
+				next = s.Next(next)
+				if got := next.Format("2006-01-02 15:04"); got != want {
+					t.Fatalf("Next() = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextNeverMatches(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := s.Next(start); !next.IsZero() {
+		t.Errorf("Next() = %s, want the zero time", next)
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	s, err := Parse("@daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2026, 10, 17, 0, 0, 0, 0, loc)
+	if next := s.Next(start.In(loc)); !next.Equal(want) || next.Location() != loc {
+		t.Errorf("Next() = %s, want %s", next, want)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	s, err := Parse("@every 6h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := s.Next(start); !next.Equal(start.Add(6 * time.Hour)) {
+		t.Errorf("Next() = %s, want 6h after start", next)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"", "expected 5 fields"},
+		{"0 3 * *", "expected 5 fields"},
+		{"0 3 * * * *", "expected 5 fields"},
+		{"60 * * * *", "minute: 60 is outside 0-59"},
+		{"* 24 * * *", "hour: 24 is outside 0-23"},
+		{"* * 0 * *", "day of month: 0 is outside 1-31"},
+		{"* * * 13 *", "month: 13 is outside 1-12"},
+		{"* * * * 8", "day of week: 8 is outside 0-7"},
+		{"*/0 * * * *", `minute: invalid step "0"`},
+		{"*/x * * * *", `minute: invalid step "x"`},
+		{"5-1 * * * *", `minute: range "5-1" is reversed`},
+		{"a * * * *", `minute: invalid value "a"`},
+		{"@every 30s", "interval must be at least 1m"},
+		{"@every soon", "invalid duration"},
+		{"@fortnightly", "expected 5 fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package state manages the directory go-unraid-clean keeps data in between
+// runs: the audit journal, reports written by the serve daemon and the
+// daemon's record of which items each scan flagged.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/report"
+)
+
+const reportsDir = "reports"
+
+// Dir is the state directory. Relative journal and quarantine paths in the
+// config are resolved against it.
+type Dir struct {
+	root       string
+	journal    string
+	quarantine string
+}
+
+func FromConfig(cfg config.Config) Dir {
+	root := cfg.StateDir
+	if root == "" {
+		root = "."
+	}
+	return Dir{root: filepath.Clean(root), journal: cfg.Journal.Path, quarantine: cfg.Quarantine.Path}
+}
+
+func (d Dir) Root() string {
+	return d.root
+}
+
+// Path returns a path inside the state directory.
+func (d Dir) Path(elem ...string) string {
+	return filepath.Join(append([]string{d.root}, elem...)...)
+}
+
+// Resolve returns p unchanged when it is empty or absolute and relative to
+// the state directory otherwise.
+func (d Dir) Resolve(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(d.root, p)
+}
+
+func (d Dir) JournalPath() string {
+	return d.Resolve(d.journal)
+}
+
+// QuarantinePath is "" when quarantine is not configured.
+func (d Dir) QuarantinePath() string {
+	return d.Resolve(d.quarantine)
+}
+
+// ReadJSON decodes a state file into v. It returns false when the file does
+// not exist yet.
+func (d Dir) ReadJSON(name string, v any) (bool, error) {
+	data, err := os.ReadFile(d.Path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read state %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("parse state %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// WriteJSON replaces a state file atomically.
+func (d Dir) WriteJSON(name string, v any) error {
+	payload, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal state %s: %w", name, err)
+	}
+	path := d.Path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(payload, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write state %s: %w", name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write state %s: %w", name, err)
+	}
+	return nil
+}
+
+// SaveReport writes a report to the reports directory as
+// <prefix>-<generated_at>.json and returns its path.
+func (d Dir) SaveReport(prefix string, rep *report.Report) (string, error) {
+	dir := d.Path(reportsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create reports dir: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", prefix, rep.GeneratedAt.UTC().Format("20060102-150405")))
+	if err := report.WriteJSON(path, rep); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Reports lists saved reports with the given prefix, newest first.
+func (d Dir) Reports(prefix string) ([]string, error) {
+	entries, err := os.ReadDir(d.Path(reportsDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list reports: %w", err)
+	}
+	var out []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix+"-") || !strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".progress.json") {
+			continue
+		}
+		out = append(out, filepath.Join(d.Path(reportsDir), name))
+	}
+	// Names embed the UTC timestamp, so they sort chronologically.
+	sort.Sort(sort.Reverse(sort.StringSlice(out)))
+	return out, nil
+}
+
+// FindReport returns the saved report with the given prefix whose file name,
+// with or without .json, is name; the newest one when name is "" or "latest".
+// It returns "" when none matches.
+func (d Dir) FindReport(prefix string, name string) (string, error) {
+	reports, err := d.Reports(prefix)
+	if err != nil || len(reports) == 0 {
+		return "", err
+	}
+	if name == "" || name == "latest" {
+		return reports[0], nil
+	}
+	name = strings.TrimSuffix(name, ".json")
+	for _, path := range reports {
+		if ReportID(path) == name {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// ReportID is the file name of a saved report without .json.
+func ReportID(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), ".json")
+}
+
+// PruneReports removes all but the newest keep reports with the given prefix,
+// along with their apply progress files.
+func (d Dir) PruneReports(prefix string, keep int) ([]string, error) {
+	reports, err := d.Reports(prefix)
+	if err != nil || len(reports) <= keep {
+		return nil, err
+	}
+	removed := reports[keep:]
+	for _, path := range removed {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove report: %w", err)
+		}
+		if err := os.Remove(path + ".progress.json"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("remove progress: %w", err)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,153 @@
+// Package unraid resolves user share paths to the array disks and pools that
+// actually hold the files.
+package unraid
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// notDisks are entries under the mount root that are not array disks or
+// pools.
+var notDisks = map[string]bool{
+	"user":      true,
+	"user0":     true,
+	"disks":     true,
+	"remotes":   true,
+	"addons":    true,
+	"rootshare": true,
+}
+
+type Layout struct {
+	root  string
+	disks []string
+}
+
+type DiskBytes struct {
+	Disk  string
+	Bytes int64
+}
+
+// Detect lists the array disks (disk1..N) and pools (cache, ...) mounted
+// under root, typically /mnt.
+func Detect(root string) (*Layout, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	layout := &Layout{root: path.Clean(root)}
+	for _, entry := range entries {
+		if !entry.IsDir() || notDisks[entry.Name()] || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		layout.disks = append(layout.disks, entry.Name())
+	}
+	SortDisks(layout.disks)
+	return layout, nil
+}
+
+func (l *Layout) Disks() []string {
+	return l.disks
+}
+
+// Locate returns the bytes each disk holds for the given host paths (files or
+// directories). Paths under <root>/user are looked up on every disk; paths
+// already on a disk are attributed to it. Other paths are ignored.
+func (l *Layout) Locate(paths []string) ([]DiskBytes, error) {
+	totals := map[string]int64{}
+	for _, p := range paths {
+		clean := path.Clean(p)
+		rel, ok := strings.CutPrefix(clean, l.root+"/")
+		if !ok {
+			continue
+		}
+		share, rest, _ := strings.Cut(rel, "/")
+		if share == "user" || share == "user0" {
+			for _, disk := range l.disks {
+				bytes, err := treeBytes(path.Join(l.root, disk, rest))
+				if err != nil {
+					return nil, err
+				}
+				totals[disk] += bytes
+			}
+			continue
+		}
+		bytes, err := treeBytes(clean)
+		if err != nil {
+			return nil, err
+		}
+		totals[share] += bytes
+	}
+	out := make([]DiskBytes, 0, len(totals))
+	for disk, bytes := range totals {
+		if bytes > 0 {
+			out = append(out, DiskBytes{Disk: disk, Bytes: bytes})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return diskLess(out[i].Disk, out[j].Disk)
+	})
+	return out, nil
+}
+
+// treeBytes sums regular file sizes under p; a missing p counts as zero.
+func treeBytes(p string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(p, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	return total, err
+}
+
+// SortDisks orders disk names the way Unraid lists them.
+func SortDisks(names []string) {
+	sort.Slice(names, func(i, j int) bool {
+		return diskLess(names[i], names[j])
+	})
+}
+
+// diskLess orders array disks numerically (disk2 before disk10) and puts
+// them ahead of pools.
+func diskLess(a, b string) bool {
+	an, aDisk := diskNumber(a)
+	bn, bDisk := diskNumber(b)
+	if aDisk != bDisk {
+		return aDisk
+	}
+	if aDisk && an != bn {
+		return an < bn
+	}
+	return a < b
+}
+
+func diskNumber(name string) (int, bool) {
+	rest, ok := strings.CutPrefix(name, "disk")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,309 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-unraid-clean/internal/apply"
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/state"
+)
+
+// plannedAction is the bulk action value that applies each item's own action.
+const plannedAction = "planned"
+
+type row struct {
+	report.Item
+	Key          string
+	Status       string
+	StatusReason string
+}
+
+type indexPage struct {
+	Report      string
+	Reports     []string
+	StateDir    string
+	GeneratedAt time.Time
+	Rows        []row
+	Summary     report.Summary
+	Quarantine  bool
+	Message     string
+	Error       string
+}
+
+type confirmPage struct {
+	Report     string
+	Action     string
+	Rows       []row
+	Excluded   []string
+	TotalBytes int64
+	ByAction   map[string]int
+}
+
+func (s *Server) index(w http.ResponseWriter, r *http.Request) {
+	page := indexPage{Message: r.URL.Query().Get("msg"), Error: r.URL.Query().Get("error")}
+	cfg, err := config.Load(s.opts.ConfigPath)
+	if err != nil {
+		page.Error = err.Error()
+		s.render(w, "index.html", page)
+		return
+	}
+	page.StateDir = state.FromConfig(cfg).Root()
+	page.Quarantine = cfg.Quarantine.Path != ""
+
+	path, rep, names, err := s.loadReport(cfg, r.URL.Query().Get("report"))
+	page.Reports = names
+	if errors.Is(err, errNoReports) {
+		s.render(w, "index.html", page)
+		return
+	}
+	if err != nil {
+		page.Error = err.Error()
+		s.render(w, "index.html", page)
+		return
+	}
+	progress, err := apply.LoadProgress(apply.ProgressPath(path))
+	if err != nil {
+		page.Error = err.Error()
+	}
+	page.Report = filepath.Base(path)
+	page.GeneratedAt = rep.GeneratedAt
+	page.Summary = report.Summarize(rep)
+	page.Rows = rows(rep, progress)
+	s.render(w, "index.html", page)
+}
+
+func (s *Server) itemAction(w http.ResponseWriter, r *http.Request) {
+	reportName := r.FormValue("report")
+	_, path, rep, err := s.formReport(reportName)
+	if err != nil {
+		redirect(w, r, reportName, "error", err.Error())
+		return
+	}
+	item, ok := rep.Find(r.FormValue("key"))
+	if !ok {
+		redirect(w, r, reportName, "error", "item not found in report")
+		return
+	}
+	if !requireAction(w, r) {
+		return
+	}
+	action, err := report.NormalizeAction(r.FormValue("action"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	item.Action = action
+
+	// Single clicks behave like interactive review: the reviewer is looking
+	// at the item, so it is not revalidated.
+	outcome, err := apply.Partial(r.Context(), s.opts.ConfigPath, path, rep, []report.Item{item}, "web", false)
+	if err != nil {
+		redirect(w, r, reportName, "error", fmt.Sprintf("%s: %s", item.Title, err))
+		return
+	}
+	switch {
+	case outcome.AlreadyDone > 0:
+		redirect(w, r, reportName, "error", fmt.Sprintf("%s: already handled earlier; nothing was changed", item.Title))
+	case outcome.Skipped > 0:
+		reason := "skipped"
+		if progress, err := apply.LoadProgress(apply.ProgressPath(path)); err == nil && progress != nil {
+			if status := progress.Items[item.Key()]; status.Reason != "" {
+				reason = "skipped: " + status.Reason
+			}
+		}
+		redirect(w, r, reportName, "error", fmt.Sprintf("%s: %s", item.Title, reason))
+	default:
+		redirect(w, r, reportName, "msg", fmt.Sprintf("%s: %s", item.Title, describeAction(action)))
+	}
+}
+
+// bulkReview shows what a bulk action would do before anything is applied.
+func (s *Server) bulkReview(w http.ResponseWriter, r *http.Request) {
+	if !requireAction(w, r) {
+		return
+	}
+	reportName := r.FormValue("report")
+	cfg, path, rep, err := s.formReport(reportName)
+	if err != nil {
+		redirect(w, r, reportName, "error", err.Error())
+		return
+	}
+	progress, err := apply.LoadProgress(apply.ProgressPath(path))
+	if err != nil {
+		redirect(w, r, reportName, "error", err.Error())
+		return
+	}
+	items, excluded, err := selectItems(cfg, rep, progress, r.Form["key"], r.FormValue("action"))
+	if err != nil {
+		redirect(w, r, reportName, "error", err.Error())
+		return
+	}
+	if len(items) == 0 && len(excluded) == 0 {
+		redirect(w, r, reportName, "error", "no items selected")
+		return
+	}
+	page := confirmPage{
+		Report:   reportName,
+		Action:   r.FormValue("action"),
+		Excluded: excluded,
+		ByAction: map[string]int{},
+	}
+	for _, item := range items {
+		page.Rows = append(page.Rows, row{Item: item, Key: item.Key()})
+		page.ByAction[item.Action]++
+		if item.Action != report.ActionKeep && item.Action != report.ActionIgnore {
+			page.TotalBytes += item.Reclaimable()
+		}
+	}
+	s.render(w, "confirm.html", page)
+}
+
+func (s *Server) bulkApply(w http.ResponseWriter, r *http.Request) {
+	if !requireAction(w, r) {
+		return
+	}
+	reportName := r.FormValue("report")
+	if r.FormValue("confirm") != "yes" {
+		redirect(w, r, reportName, "error", "bulk apply was not confirmed")
+		return
+	}
+	cfg, path, rep, err := s.formReport(reportName)
+	if err != nil {
+		redirect(w, r, reportName, "error", err.Error())
+		return
+	}
+	progress, err := apply.LoadProgress(apply.ProgressPath(path))
+	if err != nil {
+		redirect(w, r, reportName, "error", err.Error())
+		return
+	}
+	items, _, err := selectItems(cfg, rep, progress, r.Form["key"], r.FormValue("action"))
+	if err != nil {
+		redirect(w, r, reportName, "error", err.Error())
+		return
+	}
+
+	outcome, err := apply.Partial(r.Context(), s.opts.ConfigPath, path, rep, items, "web", true)
+	summary := fmt.Sprintf("Done: %d, failed: %d, skipped: %d, kept: %d", outcome.Done, outcome.Failed, outcome.Skipped, outcome.Kept)
+	if outcome.AlreadyDone > 0 {
+		summary += fmt.Sprintf(", already done: %d", outcome.AlreadyDone)
+	}
+	if outcome.Remaining > 0 {
+		summary += fmt.Sprintf(", not attempted: %d", outcome.Remaining)
+	}
+	if err != nil {
+		redirect(w, r, reportName, "error", summary+"; "+err.Error())
+		return
+	}
+	redirect(w, r, reportName, "msg", summary)
+}
+
+// requireAction rejects a form without an action with 400. NormalizeAction
+// turns "" into delete, which must never happen by omission.
+func requireAction(w http.ResponseWriter, r *http.Request) bool {
+	if strings.TrimSpace(r.FormValue("action")) == "" {
+		http.Error(w, "action is required", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (s *Server) formReport(name string) (config.Config, string, *report.Report, error) {
+	cfg, err := config.Load(s.opts.ConfigPath)
+	if err != nil {
+		return cfg, "", nil, err
+	}
+	path, rep, _, err := s.loadReport(cfg, name)
+	if errors.Is(err, errNoReports) {
+		err = fmt.Errorf("no reports in %s yet", state.FromConfig(cfg).Path("reports"))
+	}
+	return cfg, path, rep, err
+}
+
+// selectItems resolves the selected keys to report items carrying the bulk
+// action. Items already handled or the action does not apply to are returned
+// by title as excluded.
+func selectItems(cfg config.Config, rep *report.Report, progress *apply.Progress, keys []string, bulkAction string) ([]report.Item, []string, error) {
+	action := ""
+	if bulkAction != plannedAction {
+		normalized, err := report.NormalizeAction(bulkAction)
+		if err != nil {
+			return nil, nil, err
+		}
+		action = normalized
+	}
+	var items []report.Item
+	var excluded []string
+	for _, key := range keys {
+		item, ok := rep.Find(key)
+		if !ok {
+			continue
+		}
+		if action != "" {
+			item.Action = action
+		}
+		normalized, err := report.NormalizeAction(item.Action)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", item.Title, err)
+		}
+		item.Action = normalized
+		unsupported := report.CheckAction(item, item.Action)
+		switch {
+		case progressStatus(progress, key) == apply.StatusDone:
+			excluded = append(excluded, item.Title+" (already handled)")
+		case unsupported != nil:
+			excluded = append(excluded, fmt.Sprintf("%s (%s)", item.Title, unsupported))
+		case item.Action == report.ActionQuarantine && cfg.Quarantine.Path == "":
+			excluded = append(excluded, item.Title+" (quarantine is not configured)")
+		default:
+			items = append(items, item)
+		}
+	}
+	return items, excluded, nil
+}
+
+func rows(rep *report.Report, progress *apply.Progress) []row {
+	out := make([]row, 0, len(rep.Items))
+	for _, item := range rep.Items {
+		key := item.Key()
+		r := row{Item: item, Key: key}
+		if progress != nil {
+			r.Status = progress.Items[key].Status
+			r.StatusReason = progress.Items[key].Reason
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func progressStatus(progress *apply.Progress, key string) string {
+	if progress == nil {
+		return ""
+	}
+	return progress.Items[key].Status
+}
+
+func describeAction(action string) string {
+	switch action {
+	case report.ActionKeep:
+		return "kept"
+	case report.ActionIgnore:
+		return "added to exceptions"
+	case report.ActionDelete:
+		return "deleted"
+	case report.ActionDeleteFiles:
+		return "files deleted"
+	case report.ActionKeepLastSeason:
+		return "kept last season"
+	case report.ActionQuarantine:
+		return "quarantined"
+	default:
+		return strings.ReplaceAll(action, "_", " ")
+	}
+}
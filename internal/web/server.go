@@ -0,0 +1,214 @@
+// Package web serves a browser UI for reviewing reports. Actions go through
+// apply.Partial, so they are journaled, recorded in the report's progress file
+// and saved to the config exactly as with the apply command.
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"go-unraid-clean/internal/config"
+	"go-unraid-clean/internal/daemon"
+	"go-unraid-clean/internal/logging"
+	"go-unraid-clean/internal/report"
+	"go-unraid-clean/internal/state"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var errNoReports = errors.New("no reports")
+
+type Options struct {
+	ConfigPath string
+	// ReportPath pins the UI to one JSON report instead of the scan reports
+	// the serve daemon keeps in the state directory.
+	ReportPath string
+}
+
+type Server struct {
+	opts  Options
+	auth  config.Web
+	pages *template.Template
+}
+
+func New(opts Options, auth config.Web) (*Server, error) {
+	pages, err := template.New("").Funcs(template.FuncMap{
+		"gib":  formatGiB,
+		"date": formatDate,
+	}).ParseFS(templateFS, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("parse templates: %w", err)
+	}
+	return &Server{opts: opts, auth: auth, pages: pages}, nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.index)
+	mux.HandleFunc("POST /items/action", s.itemAction)
+	mux.HandleFunc("POST /bulk/review", s.bulkReview)
+	mux.HandleFunc("POST /bulk/apply", s.bulkApply)
+	return s.requireAuth(sameOrigin(mux))
+}
+
+// CheckExposure refuses to serve the UI, which can delete media, on anything
+// but a loopback address without basic auth.
+func CheckExposure(addr string, auth config.Web) error {
+	if auth.Username != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("web: listen address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("web: refusing to listen on %s without web.username and web.password; set them or listen on 127.0.0.1", addr)
+}
+
+// ListenAndServe serves handler on addr until ctx is cancelled. Requests run
+// under ctx, so an apply in progress stops at the next item on shutdown.
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	logging.L().Info().Str("addr", addr).Msg("Listening")
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shut down http server: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) requireAuth(next http.Handler) http.Handler {
+	if s.auth.Username == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(s.auth.Username)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(s.auth.Password)) == 1
+		if !ok || !userOK || !passOK {
+			w.Header().Set("WWW-Authenticate", `Basic realm="go-unraid-clean"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin rejects cross-site form posts, which browsers would otherwise
+// send with cached basic auth credentials.
+func sameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if origin := r.Header.Get("Origin"); origin != "" {
+				u, err := url.Parse(origin)
+				if err != nil || u.Host != r.Host {
+					http.Error(w, "cross-origin request refused", http.StatusForbidden)
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loadReport returns the path and contents of the report to show, with the
+// file names of all saved scan reports. name picks one of them; the newest is
+// used when it is empty.
+func (s *Server) loadReport(cfg config.Config, name string) (string, *report.Report, []string, error) {
+	if s.opts.ReportPath != "" {
+		rep, err := report.ReadJSON(s.opts.ReportPath)
+		return s.opts.ReportPath, rep, nil, err
+	}
+	dir := state.FromConfig(cfg)
+	paths, err := dir.Reports(daemon.ScanPrefix)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if len(paths) == 0 {
+		return "", nil, nil, errNoReports
+	}
+	names := make([]string, 0, len(paths))
+	for _, p := range paths {
+		names = append(names, filepath.Base(p))
+	}
+	path, err := dir.FindReport(daemon.ScanPrefix, name)
+	if err != nil {
+		return "", nil, names, err
+	}
+	if path == "" {
+		return "", nil, names, fmt.Errorf("unknown report %q", name)
+	}
+	rep, err := report.ReadJSON(path)
+	return path, rep, names, err
+}
+
+func (s *Server) render(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.pages.ExecuteTemplate(w, name, data); err != nil {
+		logging.L().Error().Err(err).Str("template", name).Msg("Render failed")
+	}
+}
+
+// redirect sends the browser back to the report with a flash message.
+func redirect(w http.ResponseWriter, r *http.Request, reportName string, key string, message string) {
+	query := url.Values{}
+	if reportName != "" {
+		query.Set("report", reportName)
+	}
+	query.Set(key, message)
+	http.Redirect(w, r, "/?"+query.Encode(), http.StatusSeeOther)
+}
+
+func formatGiB(bytes int64) string {
+	if bytes <= 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%.2f", float64(bytes)/(1024*1024*1024))
+}
+
+func formatDate(val any) string {
+	switch t := val.(type) {
+	case time.Time:
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format("2006-01-02 15:04")
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return t.Local().Format("2006-01-02")
+	}
+	return ""
+}